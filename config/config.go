@@ -11,27 +11,41 @@ import (
 type Config struct {
 	Defaults ScanDefaults           `yaml:"defaults"`
 	Profiles map[string]ScanProfile `yaml:"profiles"`
+	Plugins  []PluginConfig         `yaml:"plugins"`
 }
 
 // ScanDefaults holds default scan settings
 type ScanDefaults struct {
-	Path   string `yaml:"path"`
-	Output string `yaml:"output"`
-	NoWarn bool   `yaml:"no_warn"`
-	Filter string `yaml:"filter"`
-	Key    string `yaml:"key"`
-	Value  string `yaml:"value"`
+	Path    string   `yaml:"path"`
+	Output  string   `yaml:"output"`
+	NoWarn  bool     `yaml:"no_warn"`
+	Filter  string   `yaml:"filter"`
+	Key     string   `yaml:"key"`
+	Value   string   `yaml:"value"`
+	Comment string   `yaml:"comment"`
+	Exclude []string `yaml:"exclude"`
 }
 
 // ScanProfile represents a named configuration profile
 type ScanProfile struct {
-	Path        string `yaml:"path"`
-	Output      string `yaml:"output"`
-	NoWarn      bool   `yaml:"no_warn"`
-	Filter      string `yaml:"filter"`
-	Key         string `yaml:"key"`
-	Value       string `yaml:"value"`
-	Description string `yaml:"description"`
+	Path        string   `yaml:"path"`
+	Output      string   `yaml:"output"`
+	NoWarn      bool     `yaml:"no_warn"`
+	Filter      string   `yaml:"filter"`
+	Key         string   `yaml:"key"`
+	Value       string   `yaml:"value"`
+	Comment     string   `yaml:"comment"`
+	Exclude     []string `yaml:"exclude"`
+	Description string   `yaml:"description"`
+}
+
+// PluginConfig declares an external parser executable
+type PluginConfig struct {
+	Name     string   `yaml:"name"`
+	Command  string   `yaml:"command"`
+	Args     []string `yaml:"args"`
+	Patterns []string `yaml:"patterns"`
+	Timeout  string   `yaml:"timeout"`
 }
 
 // LoadConfig loads the config file from ~/.konfetti.yaml
@@ -76,12 +90,14 @@ func GetSampleConfig() string {
 
 # Default settings applied to all scans (can be overridden by CLI flags)
 defaults:
-  output: text        # Default output format: text, json, table
+  output: text        # Default output format: text, json, ndjson, table
   no_warn: false      # Suppress warning messages
   # path: /etc        # Default scan path (omit to use current directory)
   # filter: ""        # Default filename filter
   # key: ""           # Default key filter
   # value: ""         # Default value filter
+  # comment: ""       # Default comment filter
+  # exclude: ["*.bak", "testdata/"]  # Extra paths to skip (.gitignore syntax)
 
 # Named profiles for common scanning scenarios
 profiles:
@@ -119,6 +135,21 @@ profiles:
     output: table
     no_warn: true
 
+  # Example: Settings documented as deprecated or temporary
+  deprecated:
+    description: "Find settings whose comments mention deprecation"
+    comment: deprecated
+    output: text
+
+# External parsers for formats Konfetti doesn't know. The file is piped to the
+# command's stdin; it must print a JSON object of settings to stdout.
+# plugins:
+#   - name: appliance
+#     command: /usr/local/bin/konfetti-appliance
+#     args: ["--flat"]
+#     patterns: [".apx", "appliance-*.dump"]
+#     timeout: 5s     # default 10s
+
 # Usage:
 #   konfetti scan                    # Uses defaults
 #   konfetti scan --profile debug    # Uses debug profile
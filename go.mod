@@ -3,6 +3,7 @@ module Konfetti
 go 1.24.4
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/manifoldco/promptui v0.9.0
 	github.com/urfave/cli/v2 v2.27.7
 	gopkg.in/yaml.v3 v3.0.1
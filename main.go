@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -21,9 +26,67 @@ import (
 var Version = "2.0.0"
 
 type ConfigResult struct {
-	File     string                 `json:"file"`
-	Format   string                 `json:"format"`
-	Settings map[string]interface{} `json:"settings"`
+	File string `json:"file"`
+	// Aliases are the other paths the scan reached File by, through
+	// symlinks or hard links.
+	Aliases    []string                   `json:"aliases,omitempty"`
+	Format     string                     `json:"format"`
+	DetectedBy string                     `json:"detected_by"`
+	Encoding   string                     `json:"encoding,omitempty"`
+	Settings   map[string]interface{}     `json:"settings"`
+	Positions  map[string]parser.Position `json:"positions,omitempty"`
+	Comments   map[string]parser.Comment  `json:"comments,omitempty"`
+	Normalized map[string]parser.Value    `json:"normalized,omitempty"`
+	Embedded   map[string]string          `json:"embedded,omitempty"`
+	Error      *parser.ParseError         `json:"error,omitempty"`
+	// Keys is the order Settings are printed in, JSON included.
+	Keys []string `json:"-"`
+}
+
+// MarshalJSON writes settings in Keys order rather than encoding/json's
+// sorted map order.
+func (r ConfigResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		File       string                     `json:"file"`
+		Aliases    []string                   `json:"aliases,omitempty"`
+		Format     string                     `json:"format"`
+		DetectedBy string                     `json:"detected_by"`
+		Encoding   string                     `json:"encoding,omitempty"`
+		Settings   orderedSettings            `json:"settings"`
+		Positions  map[string]parser.Position `json:"positions,omitempty"`
+		Comments   map[string]parser.Comment  `json:"comments,omitempty"`
+		Normalized map[string]parser.Value    `json:"normalized,omitempty"`
+		Embedded   map[string]string          `json:"embedded,omitempty"`
+		Error      *parser.ParseError         `json:"error,omitempty"`
+	}{r.File, r.Aliases, r.Format, r.DetectedBy, r.Encoding, orderedSettings{r.Keys, r.Settings}, r.Positions, r.Comments, r.Normalized, r.Embedded, r.Error})
+}
+
+type orderedSettings struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (s orderedSettings) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range s.keys {
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(s.values[k])
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
 }
 
 func main() {
@@ -42,10 +105,23 @@ func main() {
 					&cli.StringFlag{Name: "path", Aliases: []string{"p"}, Usage: "Path to scan"},
 					&cli.StringFlag{Name: "key", Usage: "Filter by key substring"},
 					&cli.StringFlag{Name: "value", Usage: "Filter by value substring"},
+					&cli.StringFlag{Name: "comment", Usage: "Filter by substring of the comment documenting a setting"},
 					&cli.StringFlag{Name: "filter", Usage: "Filter filenames that contain substring"},
-					&cli.StringFlag{Name: "output", Usage: "Output format: text, json, table", Value: "text"},
+					&cli.StringFlag{Name: "output", Usage: "Output format: text, json, ndjson, table", Value: "text"},
 					&cli.BoolFlag{Name: "interactive", Aliases: []string{"i"}, Usage: "Enable interactive mode"},
 					&cli.BoolFlag{Name: "no-warn", Usage: "Suppress warning output for unreadable paths"},
+					&cli.StringFlag{Name: "array-notation", Usage: "List index notation in keys: bracket (a[0].b) or dot (a.0.b)", Value: "bracket"},
+					&cli.BoolFlag{Name: "infer-types", Usage: "Infer bool, number, duration and size values in key=value formats and report normalized values"},
+					&cli.BoolFlag{Name: "expand-embedded", Usage: "Expand string values holding JSON, YAML, INI or properties documents (or base64 of them) into nested settings"},
+					&cli.IntFlag{Name: "embedded-depth", Usage: "How many levels of embedded documents -expand-embedded follows", Value: 2},
+					&cli.IntFlag{Name: "jobs", Aliases: []string{"j"}, Usage: "Number of files parsed in parallel", Value: runtime.NumCPU()},
+					&cli.StringFlag{Name: "sort", Usage: "Setting order: source (as written in each file) or key (alphabetical)", Value: "source"},
+					&cli.StringSliceFlag{Name: "exclude", Usage: "Skip files and directories matching a .gitignore-style pattern (repeatable)"},
+					&cli.BoolFlag{Name: "no-default-excludes", Usage: "Also scan .git, node_modules, vendor, build output and similar directories"},
+					&cli.BoolFlag{Name: "gitignore", Usage: "Skip what .gitignore files ignore (.konfettiignore files always apply)"},
+					&cli.IntFlag{Name: "max-depth", Usage: "Directory levels to descend below each scanned path (0 means no limit)"},
+					&cli.BoolFlag{Name: "follow-symlinks", Usage: "Descend into symlinked directories (loops are detected; files reached twice are listed once)"},
+					&cli.DurationFlag{Name: "timeout", Usage: "Stop scanning after this long and print the results so far (e.g. 30s; 0 means no limit)"},
 				},
 				Action: scanCommand,
 			},
@@ -122,13 +198,19 @@ func scanCommand(c *cli.Context) error {
 		}
 	}
 
+	if err := registerPlugins(cfg.Plugins); err != nil {
+		return err
+	}
+
 	// Start with defaults from config
 	path := cfg.Defaults.Path
 	filterKey := cfg.Defaults.Key
 	filterValue := cfg.Defaults.Value
+	filterComment := cfg.Defaults.Comment
 	filterName := cfg.Defaults.Filter
 	outputFormat := cfg.Defaults.Output
 	noWarn := cfg.Defaults.NoWarn
+	excludes := cfg.Defaults.Exclude
 
 	// Apply profile if specified
 	if profileName := c.String("profile"); profileName != "" {
@@ -142,12 +224,18 @@ func scanCommand(c *cli.Context) error {
 			if profile.Value != "" {
 				filterValue = profile.Value
 			}
+			if profile.Comment != "" {
+				filterComment = profile.Comment
+			}
 			if profile.Filter != "" {
 				filterName = profile.Filter
 			}
 			if profile.Output != "" {
 				outputFormat = profile.Output
 			}
+			if len(profile.Exclude) > 0 {
+				excludes = profile.Exclude
+			}
 			noWarn = profile.NoWarn
 		} else {
 			return fmt.Errorf("profile '%s' not found in ~/.konfetti.yaml", profileName)
@@ -164,6 +252,9 @@ func scanCommand(c *cli.Context) error {
 	if c.IsSet("value") {
 		filterValue = c.String("value")
 	}
+	if c.IsSet("comment") {
+		filterComment = c.String("comment")
+	}
 	if c.IsSet("filter") {
 		filterName = c.String("filter")
 	}
@@ -173,40 +264,84 @@ func scanCommand(c *cli.Context) error {
 	if c.IsSet("no-warn") {
 		noWarn = c.Bool("no-warn")
 	}
+	// -exclude adds to the configured patterns rather than replacing them
+	excludes = append(excludes, c.StringSlice("exclude")...)
 
 	interactive := c.Bool("interactive")
 
+	notation, err := parser.ParseArrayNotation(c.String("array-notation"))
+	if err != nil {
+		return err
+	}
+	parse := parser.Options{IndexNotation: notation, InferTypes: c.Bool("infer-types")}
+	if c.Bool("expand-embedded") {
+		parse.EmbeddedDepth = c.Int("embedded-depth")
+	}
+
+	walk := scanner.Options{
+		Exclude:        excludes,
+		GitIgnore:      c.Bool("gitignore"),
+		MaxDepth:       c.Int("max-depth"),
+		FollowSymlinks: c.Bool("follow-symlinks"),
+	}
+	if !c.Bool("no-default-excludes") {
+		walk.Exclude = append(append([]string{}, scanner.DefaultExcludes...), excludes...)
+	}
+	if walk.MaxDepth < 0 {
+		return fmt.Errorf("invalid max depth %d: must not be negative", walk.MaxDepth)
+	}
+
+	jobs := c.Int("jobs")
+	if jobs < 1 {
+		return fmt.Errorf("invalid jobs %d: need at least 1", jobs)
+	}
+
+	sortBy := c.String("sort")
+	if sortBy != "source" && sortBy != "key" {
+		return fmt.Errorf("invalid sort order %q: use source or key", sortBy)
+	}
+
+	timeout := c.Duration("timeout")
+	if timeout < 0 {
+		return fmt.Errorf("invalid timeout %s: must not be negative", timeout)
+	}
+
+	// Ctrl-C stops the scan and prints what was found so far; a second one
+	// kills the process as usual.
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-sigCtx.Done()
+		stop()
+	}()
+	ctx := sigCtx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// STDIN mode: no path provided but data is piped in
 	if path == "" && hasStdinData() {
 		data, err := os.ReadFile("/dev/stdin")
 		if err != nil {
 			return err
 		}
-		parsed, format := parser.ParseBytes(data)
-		// Apply filters
-		if filterKey != "" || filterValue != "" {
-			filtered := make(map[string]interface{})
-			for k, v := range parsed {
-				keyMatch := filterKey == "" || strings.Contains(strings.ToLower(k), strings.ToLower(filterKey))
-				valMatch := filterValue == "" || strings.Contains(strings.ToLower(fmt.Sprintf("%v", v)), strings.ToLower(filterValue))
-				if keyMatch && valMatch {
-					filtered[k] = v
-				}
-			}
-			parsed = filtered
-		}
-		result := []ConfigResult{{File: "stdin", Format: format, Settings: parsed}}
+		result := []ConfigResult{filterResult("stdin", parser.ParseData(data, parse), filterKey, filterValue, filterComment)}
+		sortResults(result, sortBy)
 		switch outputFormat {
 		case "json":
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
 			enc.Encode(result)
+		case "ndjson":
+			json.NewEncoder(os.Stdout).Encode(result[0])
 		case "table":
 			printTable(result)
 		default:
-			fmt.Printf("File: stdin [%s]\n", format)
-			for k, v := range parsed {
-				fmt.Printf("  %s = %v\n", k, v)
+			fmt.Printf("File: stdin [%s]\n", formatLabel(result[0]))
+			for _, k := range result[0].Keys {
+				printSetting(result[0], k, "")
 			}
 		}
 		return nil
@@ -233,26 +368,53 @@ func scanCommand(c *cli.Context) error {
 			fmt.Println("Using default scan paths")
 			// Will handle in getDefaultScanPaths()
 			paths := getDefaultScanPaths()
-			return runScan(paths, filterName, filterKey, filterValue, outputFormat, noWarn)
+			return runScan(ctx, paths, walk, parse, filterName, filterKey, filterValue, filterComment, outputFormat, sortBy, jobs, noWarn)
 		}
 	}
 
-	return runScan([]string{path}, filterName, filterKey, filterValue, outputFormat, noWarn)
+	return runScan(ctx, []string{path}, walk, parse, filterName, filterKey, filterValue, filterComment, outputFormat, sortBy, jobs, noWarn)
 }
 
-func runScan(paths []string, filterName, filterKey, filterValue, outputFormat string, suppressWarn bool) error {
-	exts := []string{".json", ".yaml", ".yml", ".xml", ".conf", ".config", ".txt", ".ini", ".properties"}
-	results, scanErrors := ScanAndFilter(paths, exts, filterName, filterKey, filterValue)
+func runScan(ctx context.Context, paths []string, walk scanner.Options, parse parser.Options, filterName, filterKey, filterValue, filterComment, outputFormat, sortBy string, jobs int, suppressWarn bool) error {
+	exts := parser.Patterns()
+	if outputFormat == "ndjson" {
+		return streamNDJSON(ctx, paths, exts, walk, parse, filterName, filterKey, filterValue, filterComment, sortBy, jobs, suppressWarn)
+	}
+	results, scanErrors := ScanAndFilter(ctx, paths, exts, walk, parse, filterName, filterKey, filterValue, filterComment, jobs)
+	sortResults(results, sortBy)
+	note := partialNote(ctx)
 
-	fmt.Printf("Matched %d config files:\n", len(results))
+	failed := 0
+	for _, r := range results {
+		if r.Error != nil {
+			failed++
+		}
+	}
+
+	fmt.Printf("Matched %d config files:\n", len(results)-failed)
 	if len(scanErrors) > 0 && !suppressWarn {
 		fmt.Println("Encountered the following errors while scanning:")
 		for _, err := range scanErrors {
 			fmt.Printf("  [WARN] %s\n", err)
 		}
 	}
+	if failed > 0 {
+		fmt.Printf("Failed to parse %d config files:\n", failed)
+		for _, r := range results {
+			if r.Error == nil {
+				continue
+			}
+			fmt.Printf("  [ERROR] %s\n", r.Error)
+			if r.Error.Snippet != "" {
+				fmt.Printf("          | %s\n", r.Error.Snippet)
+			}
+		}
+	}
 	if len(results) == 0 {
 		fmt.Println("No matches found.")
+		if note != "" {
+			fmt.Println(note)
+		}
 		return nil
 	}
 
@@ -265,17 +427,73 @@ func runScan(paths []string, filterName, filterKey, filterValue, outputFormat st
 		printTable(results)
 	default:
 		for _, r := range results {
-			fmt.Printf("File: %s [%s]\n", r.File, r.Format)
-			for k, v := range r.Settings {
-				fmt.Printf("  %s = %v\n", k, v)
+			if r.Error != nil {
+				continue
+			}
+			fmt.Printf("File: %s [%s]\n", r.File, formatLabel(r))
+			if len(r.Aliases) > 0 {
+				fmt.Printf("  (also at %s)\n", strings.Join(r.Aliases, ", "))
+			}
+			for _, k := range r.Keys {
+				where := ""
+				if p, ok := r.Positions[k]; ok {
+					where = fmt.Sprintf("%s:%d", r.File, p.Line)
+				}
+				printSetting(r, k, where)
 			}
 			fmt.Println("---")
 		}
 	}
+	if outputFormat != "json" {
+		fmt.Printf("Summary: %d parsed, %d failed to parse, %d warnings\n", len(results)-failed, failed, len(scanErrors))
+		if note != "" {
+			fmt.Println(note)
+		}
+	} else if note != "" {
+		// keep stdout valid JSON
+		fmt.Fprintln(os.Stderr, note)
+	}
 
 	return nil
 }
 
+// streamNDJSON writes each result as a line of JSON as soon as its file is
+// parsed, in walk order. Warnings go to stderr so stdout stays valid NDJSON.
+func streamNDJSON(ctx context.Context, paths, exts []string, walk scanner.Options, parse parser.Options, filterName, filterKey, filterValue, filterComment, sortBy string, jobs int, suppressWarn bool) error {
+	enc := json.NewEncoder(os.Stdout)
+	var encodeErr error
+	// results are written before later aliases of their file can be known
+	scanErrors := ScanStream(ctx, paths, exts, walk, parse, filterName, filterKey, filterValue, filterComment, jobs, func(r ConfigResult) {
+		if sortBy == "key" {
+			sort.Strings(r.Keys)
+		}
+		if encodeErr == nil {
+			encodeErr = enc.Encode(r)
+		}
+	}, nil)
+	if !suppressWarn {
+		for _, err := range scanErrors {
+			fmt.Fprintf(os.Stderr, "[WARN] %s\n", err)
+		}
+	}
+	if note := partialNote(ctx); note != "" {
+		fmt.Fprintln(os.Stderr, note)
+	}
+	return encodeErr
+}
+
+// partialNote explains why a scan stopped before reaching every file, or is
+// "" when it ran to the end.
+func partialNote(ctx context.Context) string {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "[WARN] Scan timed out; results are partial."
+	case ctx.Err() != nil:
+		return "[WARN] Scan interrupted; results are partial."
+	}
+	return ""
+}
+
 // ---------------- Explore Command ----------------
 func exploreCommand(c *cli.Context) error {
 	fmt.Println("🧭 Interactive explorer coming soon (think Bubble Tea TUI)")
@@ -362,42 +580,244 @@ func getDefaultScanPaths() []string {
 	return paths
 }
 
+// registerPlugins adds the external parsers declared in ~/.konfetti.yaml.
+func registerPlugins(plugins []config.PluginConfig) error {
+	for _, pl := range plugins {
+		if pl.Name == "" || pl.Command == "" {
+			return fmt.Errorf("plugin in ~/.konfetti.yaml needs both name and command")
+		}
+		var timeout time.Duration
+		if pl.Timeout != "" {
+			d, err := time.ParseDuration(pl.Timeout)
+			if err != nil {
+				return fmt.Errorf("plugin '%s': invalid timeout: %w", pl.Name, err)
+			}
+			timeout = d
+		}
+		parser.Register(parser.NewPlugin(pl.Name, pl.Command, pl.Args, pl.Patterns, timeout))
+	}
+	return nil
+}
+
+// formatLabel names a result's format, noting when it wasn't chosen by file
+// name and when the file isn't plain UTF-8.
+func formatLabel(r ConfigResult) string {
+	label := r.Format
+	if r.DetectedBy != "" && r.DetectedBy != parser.DetectedByName {
+		label += ", by " + r.DetectedBy
+	}
+	if r.Encoding != "" && r.Encoding != parser.EncodingUTF8 {
+		label += ", " + r.Encoding
+	}
+	return label
+}
+
+// location is "file:line" for settings with a known position, or just the
+// file.
+func location(r ConfigResult, key string) string {
+	if p, ok := r.Positions[key]; ok {
+		return fmt.Sprintf("%s:%d", r.File, p.Line)
+	}
+	return r.File
+}
+
+// printSetting prints one setting of a text report, preceded by its leading
+// comment and followed by its inferred type, where it is defined, the kind
+// of document it was embedded in and its trailing comment.
+func printSetting(r ConfigResult, key, where string) {
+	c := r.Comments[key]
+	if c.Leading != "" {
+		for _, line := range strings.Split(c.Leading, "\n") {
+			fmt.Printf("  # %s\n", line)
+		}
+	}
+	line := fmt.Sprintf("  %s = %v", key, r.Settings[key])
+	if n, ok := r.Normalized[key]; ok && n.Type != parser.TypeString {
+		if raw := fmt.Sprintf("%v", r.Settings[key]); raw == n.String() {
+			line += fmt.Sprintf(" (%s)", n.Type)
+		} else {
+			line += fmt.Sprintf(" (%s: %s)", n.Type, n)
+		}
+	}
+	if where != "" {
+		line += "  (" + where + ")"
+	}
+	if parent := parser.EmbeddedParent(r.Embedded, key); parent != "" {
+		line += "  [embedded " + r.Embedded[parent] + "]"
+	}
+	if c.Trailing != "" {
+		line += "  # " + c.Trailing
+	}
+	fmt.Println(line)
+}
+
 func printTable(results []ConfigResult) {
 	fmt.Printf("%-40s | %-20s | %-30s | %-8s\n", "File", "Setting", "Value", "Format")
 	fmt.Println(strings.Repeat("-", 110))
 	for _, r := range results {
-		for k, v := range r.Settings {
-			fmt.Printf("%-40s | %-20s | %-30s | %-8s\n", r.File, k, fmt.Sprintf("%v", v), r.Format)
+		for _, k := range r.Keys {
+			fmt.Printf("%-40s | %-20s | %-30s | %-8s\n", location(r, k), k, fmt.Sprintf("%v", r.Settings[k]), r.Format)
+		}
+	}
+}
+
+// sortResults puts results in a reproducible order: by file path, with
+// settings in source order or, for "key", alphabetically.
+func sortResults(results []ConfigResult, sortBy string) {
+	sort.SliceStable(results, func(i, j int) bool { return results[i].File < results[j].File })
+	if sortBy == "key" {
+		for _, r := range results {
+			sort.Strings(r.Keys)
 		}
 	}
 }
 
 // ---------------- Scan & Filter ----------------
-func ScanAndFilter(paths []string, exts []string, filterName, filterKey, filterValue string) ([]ConfigResult, []string) {
-	files, errors := scanner.ScanDirs(paths, exts)
+func ScanAndFilter(ctx context.Context, paths []string, exts []string, walk scanner.Options, parse parser.Options, filterName, filterKey, filterValue, filterComment string, jobs int) ([]ConfigResult, []string) {
 	var results []ConfigResult
+	aliases := make(map[string][]string)
+	scanErrors := ScanStream(ctx, paths, exts, walk, parse, filterName, filterKey, filterValue, filterComment, jobs, func(r ConfigResult) {
+		results = append(results, r)
+	}, func(path, file string) {
+		aliases[file] = append(aliases[file], path)
+	})
+	for i := range results {
+		r := &results[i]
+		for _, path := range aliases[r.File] {
+			if path != r.File && !slices.Contains(r.Aliases, path) {
+				r.Aliases = append(r.Aliases, path)
+			}
+		}
+	}
+	return results, scanErrors
+}
 
-	for _, f := range files {
-		result, format := parser.ParseFile(f)
-		if filterKey != "" || filterValue != "" {
-			filteredResult := make(map[string]interface{})
-			for k, v := range result {
-				keyMatch := filterKey == "" || strings.Contains(strings.ToLower(k), strings.ToLower(filterKey))
-				valMatch := filterValue == "" || strings.Contains(strings.ToLower(fmt.Sprintf("%v", v)), strings.ToLower(filterValue))
-				if keyMatch && valMatch {
-					filteredResult[k] = v
-				}
+// ScanStream is ScanAndFilter handing each result to emit as soon as its
+// file is parsed, in walk order, rather than building the whole list. It
+// returns the scan warnings once the walk is over, or as soon as ctx is
+// done, leaving out files cut short by it.
+//
+// Each file is parsed once and reported under its canonical path, with the
+// path it was first reached by as an alias when that differs. alias, when
+// not nil, gets every later path reaching a file along with the file's
+// canonical path.
+func ScanStream(ctx context.Context, paths []string, exts []string, walk scanner.Options, parse parser.Options, filterName, filterKey, filterValue, filterComment string, jobs int, emit func(ConfigResult), alias func(path, file string)) []string {
+	// each file yields a result, a warning, or neither when filtered out
+	type outcome struct {
+		result *ConfigResult
+		warn   string
+	}
+	var warnings []string
+	var onAlias func(path, first string)
+	if alias != nil {
+		onAlias = func(path, first string) {
+			alias(path, canonicalPath(first))
+		}
+	}
+	scanErrors := scanner.Stream(ctx, paths, exts, walk, jobs, func(f string) outcome {
+		if filterName != "" && !strings.Contains(f, filterName) {
+			return outcome{}
+		}
+		file := canonicalPath(f)
+		var aliases []string
+		if file != f {
+			aliases = []string{f}
+		}
+		parsed, err := parser.ParseContext(ctx, f, parse)
+		if err != nil {
+			if ctx.Err() != nil {
+				return outcome{}
 			}
-			result = filteredResult
+			var parseErr *parser.ParseError
+			if !errors.As(err, &parseErr) {
+				return outcome{warn: f + ": " + err.Error()}
+			}
+			// broken files are reported whatever the key/value filters
+			return outcome{result: &ConfigResult{
+				File:       file,
+				Aliases:    aliases,
+				Format:     parsed.Format,
+				DetectedBy: parsed.DetectedBy,
+				Encoding:   parsed.Encoding,
+				Settings:   map[string]interface{}{},
+				Error:      parseErr,
+			}}
+		}
+		result := filterResult(file, parsed, filterKey, filterValue, filterComment)
+		if len(result.Settings) == 0 {
+			return outcome{}
+		}
+		result.Aliases = aliases
+		return outcome{result: &result}
+	}, func(o outcome) {
+		if o.warn != "" {
+			warnings = append(warnings, o.warn)
 		}
+		if o.result != nil {
+			emit(*o.result)
+		}
+	}, onAlias)
+	return append(scanErrors, warnings...)
+}
+
+// canonicalPath resolves the symlinks in path, or returns it unchanged when
+// that fails.
+func canonicalPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return path
+}
 
-		if len(result) > 0 && (filterName == "" || strings.Contains(f, filterName)) {
-			results = append(results, ConfigResult{
-				File:     f,
-				Format:   format,
-				Settings: result,
-			})
+// filterResult keeps the settings of a parsed file whose key, value (raw or
+// normalized) and comment contain the filter substrings, in parse order.
+func filterResult(file string, parsed *parser.Result, filterKey, filterValue, filterComment string) ConfigResult {
+	result := ConfigResult{
+		File:       file,
+		Format:     parsed.Format,
+		DetectedBy: parsed.DetectedBy,
+		Encoding:   parsed.Encoding,
+		Settings:   make(map[string]interface{}),
+	}
+	if parsed.Positions != nil {
+		result.Positions = make(map[string]parser.Position)
+	}
+	if parsed.Comments != nil {
+		result.Comments = make(map[string]parser.Comment)
+	}
+	if parsed.Normalized != nil {
+		result.Normalized = make(map[string]parser.Value)
+	}
+	for _, k := range parsed.Keys {
+		v := parsed.Settings[k]
+		keyMatch := filterKey == "" || strings.Contains(strings.ToLower(k), strings.ToLower(filterKey))
+		valMatch := filterValue == "" || strings.Contains(strings.ToLower(fmt.Sprintf("%v", v)), strings.ToLower(filterValue))
+		if n, ok := parsed.Normalized[k]; ok && !valMatch {
+			// yes and on match -value true once types are inferred
+			valMatch = strings.Contains(strings.ToLower(n.String()), strings.ToLower(filterValue))
+		}
+		commentMatch := filterComment == "" || strings.Contains(strings.ToLower(parsed.Comments[k].String()), strings.ToLower(filterComment))
+		if !keyMatch || !valMatch || !commentMatch {
+			continue
+		}
+		result.Settings[k] = v
+		result.Keys = append(result.Keys, k)
+		if p, ok := parsed.Positions[k]; ok {
+			result.Positions[k] = p
+		}
+		if c, ok := parsed.Comments[k]; ok {
+			result.Comments[k] = c
+		}
+		if n, ok := parsed.Normalized[k]; ok {
+			result.Normalized[k] = n
+		}
+		// keep the markers of every document the setting is nested in
+		for parent := parser.EmbeddedParent(parsed.Embedded, k); parent != ""; parent = parser.EmbeddedParent(parsed.Embedded, parent) {
+			if result.Embedded == nil {
+				result.Embedded = make(map[string]string)
+			}
+			result.Embedded[parent] = parsed.Embedded[parent]
 		}
 	}
-	return results, errors
+	return result
 }
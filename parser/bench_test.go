@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// benchCorpus writes a mix of formats, each a few hundred settings long.
+func benchCorpus(b *testing.B) []string {
+	dir := b.TempDir()
+	var yml, ini, env, js strings.Builder
+	js.WriteString("{")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&yml, "service%d:\n  host: host%d.example.com\n  port: %d\n", i, i, 8000+i)
+		fmt.Fprintf(&ini, "[service%d]\nhost = host%d.example.com\nport = %d\n", i, i, 8000+i)
+		fmt.Fprintf(&env, "SERVICE%d_HOST=host%d.example.com # host\n", i, i)
+		if i > 0 {
+			js.WriteString(",")
+		}
+		fmt.Fprintf(&js, `"service%d": {"host": "host%d.example.com", "port": %d}`, i, i, 8000+i)
+	}
+	js.WriteString("}")
+
+	var paths []string
+	for i := 0; i < 10; i++ {
+		for name, content := range map[string]string{"app.yaml": yml.String(), "app.ini": ini.String(), ".env": env.String(), "app.json": js.String()} {
+			path := filepath.Join(dir, fmt.Sprint(i), name)
+			os.MkdirAll(filepath.Dir(path), 0755)
+			os.WriteFile(path, []byte(content), 0644)
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func BenchmarkParse(b *testing.B) {
+	paths := benchCorpus(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, path := range paths {
+			Parse(path, Options{})
+		}
+	}
+}
+
+// BenchmarkParseParallel parses the same corpus from GOMAXPROCS goroutines;
+// compare its ns/op against BenchmarkParse to see how Parse scales.
+func BenchmarkParseParallel(b *testing.B) {
+	paths := benchCorpus(b)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			for _, path := range paths {
+				Parse(path, Options{})
+			}
+		}
+	})
+}
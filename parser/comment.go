@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// Comment is the documentation written next to a setting, with comment
+// markers stripped.
+type Comment struct {
+	// Leading is the block of comment lines directly above the setting.
+	Leading string `json:"leading,omitempty"`
+	// Trailing is the comment at the end of the setting's line.
+	Trailing string `json:"trailing,omitempty"`
+}
+
+// String joins the leading and trailing comments, for display and search.
+func (c Comment) String() string {
+	if c.Leading == "" || c.Trailing == "" {
+		return c.Leading + c.Trailing
+	}
+	return c.Leading + "\n" + c.Trailing
+}
+
+// CommentParser is implemented by parsers that keep the comments documenting
+// each setting. Parse uses it when available.
+type CommentParser interface {
+	Parser
+	// ParseComments returns the comments of the flattened keys Parse would
+	// return. Keys without comments may be left out.
+	ParseComments(r io.Reader) (map[string]Comment, error)
+}
+
+// documentParser is implemented by parsers that read settings, positions and
+// comments in one pass, so a document is not decoded again for its comments.
+type documentParser interface {
+	readDocument(data []byte) (map[string]interface{}, map[string]Position, map[string]Comment, error)
+}
+
+// documentComments collects the comments of settings: those parseDocument
+// already found, or else from p if it can report them. Comments are extra
+// context, so a failure just means there are none.
+func documentComments(p Parser, data []byte, settings map[string]interface{}, comments map[string]Comment) map[string]Comment {
+	if comments == nil {
+		cp, ok := p.(CommentParser)
+		if !ok {
+			return nil
+		}
+		var err error
+		if comments, err = cp.ParseComments(bytes.NewReader(data)); err != nil {
+			return nil
+		}
+	}
+	kept := make(map[string]Comment)
+	for k, c := range comments {
+		if _, ok := settings[k]; ok && c != (Comment{}) {
+			kept[k] = c
+		}
+	}
+	return kept
+}
+
+// lineComments builds comments for line-based formats from the position of
+// each key: the run of comment lines directly above it, which a blank or
+// any other line ends, and whatever trailing returns for its own line.
+// markers are the characters that start a comment line.
+func lineComments(text, markers string, positions map[string]Position, trailing func(line string) string) map[string]Comment {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	comments := make(map[string]Comment)
+	for key, p := range positions {
+		if p.Line < 1 || p.Line > len(lines) {
+			continue
+		}
+		var block []string
+		for i := p.Line - 2; i >= 0; i-- {
+			line := strings.TrimSpace(lines[i])
+			if line == "" || !strings.ContainsRune(markers, rune(line[0])) {
+				break
+			}
+			block = append([]string{line}, block...)
+		}
+		c := Comment{Leading: commentText(strings.Join(block, "\n"), markers)}
+		if trailing != nil {
+			c.Trailing = commentText(trailing(lines[p.Line-1]), markers)
+		}
+		if c != (Comment{}) {
+			comments[key] = c
+		}
+	}
+	return comments
+}
+
+// commentText strips the comment markers and surrounding space from each
+// line of a comment.
+func commentText(comment, markers string) string {
+	if comment == "" {
+		return ""
+	}
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		line = strings.TrimLeft(strings.TrimSpace(line), markers)
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
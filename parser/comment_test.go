@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse_Comments(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name, content string
+		expected      map[string]Comment
+	}{
+		{"app.yaml", "# Port the server listens on\n# (privileged below 1024)\nport: 80 # default\nserver:\n  # Public host name\n  host: example.com\nlist:\n  - a # first\n", map[string]Comment{
+			"port":        {Leading: "Port the server listens on\n(privileged below 1024)", Trailing: "default"},
+			"server.host": {Leading: "Public host name"},
+			"list[0]":     {Trailing: "first"},
+		}},
+		{"app.ini", "; Database settings\n[db]\n; Server port\n# used by clients too\nport = 3306 ; default\n\nhost = localhost\nuser = \"root ; admin\"\n", map[string]Comment{
+			"db.port": {Leading: "Server port\nused by clients too", Trailing: "default"},
+		}},
+		{"app.properties", "! Greeting shown on start\ngreeting = hello # not a comment\n\n# detached\n\nname = x\n", map[string]Comment{
+			"greeting": {Leading: "Greeting shown on start"},
+		}},
+		{".env", "# API endpoint\nAPI_URL=https://x # prod\nTOKEN=\"a#b\" # secret\nPLAIN=a#b\n", map[string]Comment{
+			"API_URL": {Leading: "API endpoint", Trailing: "prod"},
+			"TOKEN":   {Trailing: "secret"},
+		}},
+	}
+	for _, tc := range cases {
+		file := filepath.Join(dir, tc.name)
+		os.WriteFile(file, []byte(tc.content), 0644)
+		result, err := Parse(file, Options{})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if len(result.Comments) != len(tc.expected) {
+			t.Errorf("%s: expected %d comments, got %d: %#v", tc.name, len(tc.expected), len(result.Comments), result.Comments)
+		}
+		for k, want := range tc.expected {
+			if got := result.Comments[k]; got != want {
+				t.Errorf("%s: expected comment %#v for %s, got %#v", tc.name, want, k, got)
+			}
+		}
+	}
+}
+
+func TestParse_NoComments(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.json")
+	os.WriteFile(file, []byte(`{"a": 1}`), 0644)
+	result, err := Parse(file, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Comments != nil {
+		t.Errorf("Expected no comments for JSON, got %v", result.Comments)
+	}
+}
+
+func TestComment_String(t *testing.T) {
+	cases := []struct {
+		comment  Comment
+		expected string
+	}{
+		{Comment{}, ""},
+		{Comment{Leading: "a"}, "a"},
+		{Comment{Trailing: "b"}, "b"},
+		{Comment{Leading: "a", Trailing: "b"}, "a\nb"},
+	}
+	for _, tc := range cases {
+		if got := tc.comment.String(); got != tc.expected {
+			t.Errorf("Expected %q, got %q", tc.expected, got)
+		}
+	}
+}
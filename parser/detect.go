@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"sort"
+)
+
+// How a file's format was chosen.
+const (
+	// DetectedByName means the file name matched a single parser's patterns.
+	DetectedByName = "name"
+	// DetectedByContent means the format was sniffed from the file content.
+	DetectedByContent = "content"
+	// DetectedByFallback means nothing recognised the file, so it was read
+	// as plain key=value text.
+	DetectedByFallback = "fallback"
+)
+
+// parserForFile picks the parser for a file by name. A plugin claiming the
+// name always wins, the user having declared it for those files; otherwise,
+// when several parsers claim the name, the most confident sniff of data
+// among them wins. Names
+// only claimed by the catch-all text parser, or not claimed at all, report
+// DetectedByFallback so the caller can try content detection first.
+func parserForFile(path string, data []byte) (Parser, string) {
+	var named []Parser
+	generic := false
+	for _, p := range Parsers() {
+		for _, pattern := range p.Patterns() {
+			if !MatchPattern(path, pattern) {
+				continue
+			}
+			if _, ok := p.(textParser); ok {
+				generic = true
+			} else {
+				named = append(named, p)
+			}
+			break
+		}
+	}
+
+	for i := len(named) - 1; i >= 0; i-- {
+		if _, ok := named[i].(*Plugin); ok {
+			return named[i], DetectedByName
+		}
+	}
+	if len(named) == 1 && !generic {
+		return named[0], DetectedByName
+	}
+	if len(named) > 0 {
+		best, bestScore := named[0], 0.0
+		for _, p := range named {
+			if score := p.Sniff(data); score >= bestScore {
+				best, bestScore = p, score
+			}
+		}
+		if bestScore > 0 {
+			return best, DetectedByContent
+		}
+		if !generic {
+			return best, DetectedByName
+		}
+	}
+	return textParser{}, DetectedByFallback
+}
+
+// detectContent tries every parser that recognises data, most confident
+// first, and returns the first that yields settings, or any valid JSON. Binary data is never
+// sniffed. It returns a nil Parser when nothing fits or ctx is done.
+func detectContent(ctx context.Context, data []byte) (Parser, map[string]interface{}, map[string]Position, map[string]Comment) {
+	if isBinary(data) {
+		return nil, nil, nil, nil
+	}
+	for _, p := range rankParsers(data) {
+		if ctx.Err() != nil {
+			break
+		}
+		m, positions, comments, err := parseDocument(ctx, p, data)
+		// valid JSON is JSON even with no settings, as in a .json file
+		if _, strict := p.(jsonParser); err == nil && (len(m) > 0 || strict) {
+			return p, m, positions, comments
+		}
+	}
+	return nil, nil, nil, nil
+}
+
+// rankParsers returns the parsers with a non-zero sniff confidence for data,
+// most confident first; ties go to the later registration.
+func rankParsers(data []byte) []Parser {
+	type scored struct {
+		p     Parser
+		score float64
+	}
+	var ranked []scored
+	parsers := Parsers()
+	for i := len(parsers) - 1; i >= 0; i-- {
+		if score := parsers[i].Sniff(data); score > 0 {
+			ranked = append(ranked, scored{parsers[i], score})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	result := make([]Parser, len(ranked))
+	for i, r := range ranked {
+		result[i] = r.p
+	}
+	return result
+}
+
+// isBinary reports whether data looks like a binary file: a NUL byte in the
+// first 8KB.
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
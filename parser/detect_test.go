@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse_DetectsContent(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name, content, format, detectedBy string
+	}{
+		{"config", "server:\n  port: 80\n", "yaml", DetectedByContent},
+		{".babelrc", `{"presets": ["env"]}`, "json", DetectedByContent},
+		{"app.config", `<?xml version="1.0"?><configuration/>`, "xml", DetectedByContent},
+		{"legacy.config", "mode=prod\n", "text", DetectedByContent},
+		{"settings.yaml", "a: 1\n", "yaml", DetectedByName},
+		{"blob.config", "\x00\x01binary", "text", DetectedByFallback},
+	}
+	for _, tc := range cases {
+		file := filepath.Join(dir, tc.name)
+		os.WriteFile(file, []byte(tc.content), 0644)
+		result, err := Parse(file, Options{})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if result.Format != tc.format || result.DetectedBy != tc.detectedBy {
+			t.Errorf("%s: expected %s by %s, got %s by %s", tc.name, tc.format, tc.detectedBy, result.Format, result.DetectedBy)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"io"
+	"strings"
+)
+
+func ParseDotenv(path string) map[string]interface{} {
+	return parsePath(dotenvParser{}, path)
+}
+
+type dotenvParser struct{}
+
+func (dotenvParser) Name() string { return "dotenv" }
+
+func (dotenvParser) Untyped() bool { return true }
+
+func (dotenvParser) Patterns() []string { return []string{".env", ".env.*"} }
+
+func (dotenvParser) Sniff(data []byte) float64 {
+	if looksLikeDotenv(string(data)) {
+		return 0.3
+	}
+	return 0
+}
+
+func (p dotenvParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	settings, _, err := p.ParsePositions(r)
+	return settings, err
+}
+
+func (dotenvParser) ParsePositions(r io.Reader) (map[string]interface{}, map[string]Position, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings, positions := parseDotenv(string(data))
+	return settings, positions, nil
+}
+
+func (dotenvParser) ParseComments(r io.Reader) (map[string]Comment, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	_, positions := parseDotenv(string(data))
+	return lineComments(string(data), "#", positions, dotenvTrailingComment), nil
+}
+
+// dotenvTrailingComment returns the " #" comment after an unquoted value, or
+// after the closing quote of a value quoted on a single line.
+func dotenvTrailingComment(line string) string {
+	eq := strings.IndexByte(line, '=')
+	if eq < 0 {
+		return ""
+	}
+	rest := strings.TrimLeft(line[eq+1:], " \t")
+	if rest != "" && (rest[0] == '"' || rest[0] == '\'' || rest[0] == '`') {
+		end := closingQuote(rest[1:], rest[0])
+		if end < 0 {
+			return ""
+		}
+		rest = rest[end+2:]
+		if i := strings.IndexByte(rest, '#'); i >= 0 {
+			return rest[i:]
+		}
+		return ""
+	}
+	if i := strings.Index(rest, " #"); i >= 0 {
+		return rest[i+1:]
+	}
+	return ""
+}
+
+// looksLikeDotenv reports whether any line uses the `export KEY=` form.
+func looksLikeDotenv(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "export ") && strings.Contains(line, "=") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDotenv reads KEY=value lines with an optional `export ` prefix.
+// Single-quoted values are literal; double-quoted values may span lines and
+// understand \n \t \r \" \\ \$ escapes; unquoted values end at an inline
+// " #" comment. ${VAR}, ${VAR:-default} and $VAR in unquoted and
+// double-quoted values are expanded from keys defined earlier in the file;
+// unknown references are left as written.
+func parseDotenv(text string) (map[string]interface{}, map[string]Position) {
+	kv := make(map[string]interface{})
+	positions := make(map[string]Position)
+	vars := make(map[string]string)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	src := text
+
+	for len(text) > 0 {
+		lineNo := strings.Count(src[:len(src)-len(text)], "\n") + 1
+		var line string
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			line, text = text[:i], text[i+1:]
+		} else {
+			line, text = text, ""
+		}
+
+		raw := line
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "export ") {
+			line = strings.TrimLeft(line[len("export "):], " \t")
+		}
+		at := Position{Line: lineNo, Column: strings.Index(raw, line) + 1}
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:eq])
+		rest := strings.TrimLeft(line[eq+1:], " \t")
+
+		var val string
+		switch {
+		case rest != "" && (rest[0] == '\'' || rest[0] == '`'):
+			val, text = readQuoted(rest, text, rest[0])
+		case rest != "" && rest[0] == '"':
+			var rawVal string
+			rawVal, text = readQuoted(rest, text, '"')
+			val = expandDotenv(rawVal, vars, true)
+		default:
+			if i := strings.Index(rest, " #"); i >= 0 {
+				rest = rest[:i]
+			}
+			val = expandDotenv(strings.TrimSpace(rest), vars, false)
+		}
+		kv[key] = val
+		vars[key] = val
+		positions[key] = at
+	}
+	return kv, positions
+}
+
+// readQuoted returns the body of a value opened by quote at the start of
+// first, consuming further lines from remaining until the closing quote.
+// A backslash-escaped quote does not close a double-quoted value.
+func readQuoted(first, remaining string, quote byte) (string, string) {
+	body := first[1:]
+	for {
+		if end := closingQuote(body, quote); end >= 0 {
+			return body[:end], remaining
+		}
+		if remaining == "" {
+			return body, ""
+		}
+		var next string
+		if i := strings.IndexByte(remaining, '\n'); i >= 0 {
+			next, remaining = remaining[:i], remaining[i+1:]
+		} else {
+			next, remaining = remaining, ""
+		}
+		body += "\n" + next
+	}
+}
+
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && quote == '"' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func expandDotenv(s string, vars map[string]string, escapes bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if escapes && c == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '"', '\\', '$':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		if c != '$' || i+1 >= len(s) {
+			b.WriteByte(c)
+			continue
+		}
+
+		if s[i+1] == '{' {
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				b.WriteString(s[i:])
+				break
+			}
+			ref := s[i+2 : i+end]
+			name, def, hasDef := ref, "", false
+			if j := strings.Index(ref, ":-"); j >= 0 {
+				name, def, hasDef = ref[:j], ref[j+2:], true
+			}
+			if v, ok := vars[name]; ok && (v != "" || !hasDef) {
+				b.WriteString(v)
+			} else if hasDef {
+				b.WriteString(def)
+			} else {
+				b.WriteString(s[i : i+end+1])
+			}
+			i += end
+			continue
+		}
+
+		j := i + 1
+		for j < len(s) && (s[j] == '_' || s[j] >= 'A' && s[j] <= 'Z' || s[j] >= 'a' && s[j] <= 'z' || s[j] >= '0' && s[j] <= '9') {
+			j++
+		}
+		if v, ok := vars[s[i+1:j]]; ok && j > i+1 {
+			b.WriteString(v)
+		} else {
+			b.WriteString(s[i:j])
+		}
+		i = j - 1
+	}
+	return b.String()
+}
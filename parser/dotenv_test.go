@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".env.local")
+	os.WriteFile(file, []byte(`# settings
+export APP_ENV=production
+HOST=db.internal # inline comment
+PORT="5432"
+URL="postgres://${HOST}:$PORT/app"
+LITERAL='${HOST} stays'
+MISSING=${NOPE}
+FALLBACK=${NOPE:-default}
+ESCAPED="line1\nline2 \"quoted\" \$HOST"
+CERT="-----BEGIN-----
+abc
+-----END-----"
+`), 0644)
+
+	result, format := ParseFile(file)
+	if format != "dotenv" {
+		t.Errorf("Expected format dotenv, got %s", format)
+	}
+	expected := map[string]interface{}{
+		"APP_ENV":  "production",
+		"HOST":     "db.internal",
+		"PORT":     "5432",
+		"URL":      "postgres://db.internal:5432/app",
+		"LITERAL":  "${HOST} stays",
+		"MISSING":  "${NOPE}",
+		"FALLBACK": "default",
+		"ESCAPED":  "line1\nline2 \"quoted\" $HOST",
+		"CERT":     "-----BEGIN-----\nabc\n-----END-----",
+	}
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("Expected %s = %q, got %q", k, v, result[k])
+		}
+	}
+	if len(result) != len(expected) {
+		t.Errorf("Expected %d keys, got %d: %v", len(expected), len(result), result)
+	}
+}
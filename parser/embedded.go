@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+)
+
+// expandEmbedded replaces string settings holding a whole JSON, INI, YAML or
+// properties document, or base64 of one, with that document's settings
+// nested under the original key, up to depth levels deep. Each expanded key
+// is recorded in result.Embedded with how its value was read, e.g. "yaml"
+// or "base64+json". Nested settings share the position of the original key.
+func expandEmbedded(result *Result, depth int) {
+	if depth <= 0 {
+		return
+	}
+	var keys []string
+	for _, k := range result.Keys {
+		s, ok := result.Settings[k].(string)
+		var inner *Result
+		if ok {
+			inner = embeddedResult(s)
+		}
+		if inner == nil {
+			keys = append(keys, k)
+			continue
+		}
+		expandEmbedded(inner, depth-1)
+
+		at, hasAt := result.Positions[k]
+		delete(result.Settings, k)
+		delete(result.Positions, k)
+		delete(result.Comments, k)
+		if result.Embedded == nil {
+			result.Embedded = make(map[string]string)
+		}
+		result.Embedded[k] = inner.Format
+		for _, ik := range inner.Keys {
+			key := embeddedKey(k, ik)
+			result.Settings[key] = inner.Settings[ik]
+			if hasAt {
+				result.Positions[key] = at
+			}
+			keys = append(keys, key)
+		}
+		for ik, format := range inner.Embedded {
+			result.Embedded[embeddedKey(k, ik)] = format
+		}
+	}
+	result.Keys = keys
+}
+
+// embeddedResult parses s if it holds a config document, directly or as
+// base64, or returns nil.
+func embeddedResult(s string) *Result {
+	text := strings.TrimSpace(s)
+	if p, m, positions := embeddedDocument(text); p != nil {
+		return &Result{Settings: m, Format: p.Name(), Positions: positions, Keys: sourceOrder(m, positions)}
+	}
+	if decoded, ok := decodeBase64Text(text); ok {
+		if p, m, positions := embeddedDocument(strings.TrimSpace(decoded)); p != nil {
+			return &Result{Settings: m, Format: "base64+" + p.Name(), Positions: positions, Keys: sourceOrder(m, positions)}
+		}
+	}
+	return nil
+}
+
+// embeddedDocument parses text as a JSON object or array, or as a multi-line
+// INI, YAML or properties document. Single-line values other than JSON are
+// never expanded: "Note: see docs" is prose, not YAML.
+func embeddedDocument(text string) (Parser, map[string]interface{}, map[string]Position) {
+	candidates := []Parser{iniParser{}, yamlParser{}, propertiesParser{}}
+	if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		if !json.Valid([]byte(text)) {
+			return nil, nil, nil
+		}
+		candidates = []Parser{jsonParser{}}
+	} else if !strings.Contains(text, "\n") {
+		return nil, nil, nil
+	}
+
+	for _, p := range candidates {
+		switch p.(type) {
+		case iniParser:
+			if !looksLikeINI(text) {
+				continue
+			}
+		case yamlParser:
+			if p.Sniff([]byte(text)) == 0 {
+				continue
+			}
+		case propertiesParser:
+			if !looksLikeProperties(text) {
+				continue
+			}
+		}
+		if m, positions, _, err := parseDocument(context.Background(), p, []byte(text)); err == nil && len(m) > 0 {
+			return p, m, positions
+		}
+	}
+	return nil, nil, nil
+}
+
+// looksLikeProperties reports whether every logical line of text separates
+// its key with = or :, so that plain prose is not taken for properties.
+func looksLikeProperties(text string) bool {
+	lines := propertiesLogicalLines(text)
+	for _, line := range lines {
+		key, _ := splitPropertiesLine(line.text)
+		rest := strings.TrimLeft(line.text[len(key):], " \t\f")
+		if key == "" || rest == "" || (rest[0] != '=' && rest[0] != ':') {
+			return false
+		}
+	}
+	return len(lines) > 0
+}
+
+// decodeBase64Text decodes standard or URL-safe base64, padded or not, when
+// the result is text. Whitespace, as in wrapped Secret data, is ignored.
+func decodeBase64Text(s string) (string, bool) {
+	s = strings.Join(strings.Fields(s), "")
+	if len(s) < 8 {
+		return "", false
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		data, err := enc.DecodeString(s)
+		if err != nil {
+			continue
+		}
+		if !utf8.Valid(data) {
+			return "", false
+		}
+		for _, c := range data {
+			if c < 0x20 && c != '\n' && c != '\r' && c != '\t' {
+				return "", false
+			}
+		}
+		return string(data), true
+	}
+	return "", false
+}
+
+// embeddedKey nests the flattened key of an embedded document under the key
+// that held it.
+func embeddedKey(parent, key string) string {
+	if strings.HasPrefix(key, "[") {
+		return parent + key
+	}
+	return parent + "." + key
+}
+
+// EmbeddedParent returns the innermost key of embedded (a Result's Embedded
+// map) that key was expanded from, or "" when key was not embedded.
+func EmbeddedParent(embedded map[string]string, key string) string {
+	parent := ""
+	for k := range embedded {
+		if len(k) > len(parent) && (strings.HasPrefix(key, k+".") || strings.HasPrefix(key, k+"[")) {
+			parent = k
+		}
+	}
+	return parent
+}
@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const configMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  app.yaml: |
+    server:
+      port: 8080
+  app.properties: |
+    db.url=jdbc:postgresql://db/app
+    db.pool=10
+  notes: |
+    Remember to rotate keys.
+    Ask ops first.
+  title: "Note: not yaml"
+`
+
+func TestParse_EmbeddedOff(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cm.yaml")
+	os.WriteFile(file, []byte(configMap), 0644)
+	result, err := Parse(file, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Embedded != nil {
+		t.Errorf("Expected no expansion by default, got %v", result.Embedded)
+	}
+	if _, ok := result.Settings["data.app.yaml"]; !ok {
+		t.Errorf("Expected data.app.yaml to stay a string, got %v", result.Settings)
+	}
+}
+
+func TestParse_EmbeddedConfigMap(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cm.yaml")
+	os.WriteFile(file, []byte(configMap), 0644)
+	result, err := Parse(file, Options{EmbeddedDepth: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"data.app.yaml.server.port":   8080,
+		"data.app.properties.db.url":  "jdbc:postgresql://db/app",
+		"data.app.properties.db.pool": "10",
+		"data.notes":                  "Remember to rotate keys.\nAsk ops first.\n",
+		"data.title":                  "Note: not yaml",
+		"metadata.name":               "app",
+	}
+	for k, v := range expected {
+		if result.Settings[k] != v {
+			t.Errorf("Expected %s = %#v, got %#v", k, v, result.Settings[k])
+		}
+	}
+	for _, k := range []string{"data.app.yaml", "data.app.properties"} {
+		if _, ok := result.Settings[k]; ok {
+			t.Errorf("Expected embedded %s to be replaced by its settings", k)
+		}
+	}
+	if !reflect.DeepEqual(result.Embedded, map[string]string{"data.app.yaml": "yaml", "data.app.properties": "properties"}) {
+		t.Errorf("Unexpected embedded markers: %v", result.Embedded)
+	}
+	if p := result.Positions["data.app.yaml.server.port"]; p.Line != 6 {
+		t.Errorf("Expected embedded setting at line 6 of the ConfigMap, got %v", p)
+	}
+	order := []string{"apiVersion", "kind", "metadata.name", "data.app.yaml.server.port", "data.app.properties.db.url", "data.app.properties.db.pool", "data.notes", "data.title"}
+	if !reflect.DeepEqual(result.Keys, order) {
+		t.Errorf("Expected embedded settings in place of their key, got %v", result.Keys)
+	}
+}
+
+func TestParse_EmbeddedBase64AndDepth(t *testing.T) {
+	inner := base64.StdEncoding.EncodeToString([]byte(`{"level": "debug"}`))
+	outer := base64.StdEncoding.EncodeToString([]byte(`{"logging": "` + inner + `", "user": "admin"}`))
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".env")
+	os.WriteFile(file, []byte("SECRET="+outer+"\nSETTINGS={\"retries\": 3}\nPASSWORD="+base64.StdEncoding.EncodeToString([]byte("hunter22"))+"\n"), 0644)
+
+	result, err := Parse(file, Options{EmbeddedDepth: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Settings["SECRET.logging.level"] != "debug" || result.Settings["SECRET.user"] != "admin" {
+		t.Errorf("Expected nested base64 JSON to be expanded, got %v", result.Settings)
+	}
+	if result.Settings["SETTINGS.retries"] != float64(3) {
+		t.Errorf("Expected JSON value to be expanded, got %v", result.Settings)
+	}
+	if _, ok := result.Settings["PASSWORD"]; !ok {
+		t.Errorf("Expected base64 of plain text to stay as is, got %v", result.Settings)
+	}
+	if result.Embedded["SECRET"] != "base64+json" || result.Embedded["SECRET.logging"] != "base64+json" {
+		t.Errorf("Unexpected embedded markers: %v", result.Embedded)
+	}
+
+	result, _ = Parse(file, Options{EmbeddedDepth: 1})
+	if result.Settings["SECRET.logging"] != inner {
+		t.Errorf("Expected depth 1 to stop after one level, got %v", result.Settings)
+	}
+}
+
+func TestParse_EmbeddedInferTypes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cm.yaml")
+	os.WriteFile(file, []byte(configMap), 0644)
+	result, _ := Parse(file, Options{EmbeddedDepth: 1, InferTypes: true})
+	if got := result.Normalized["data.app.properties.db.pool"]; got != (Value{TypeInt, int64(10)}) {
+		t.Errorf("Expected embedded properties values to be inferred, got %#v", got)
+	}
+	if got := result.Normalized["metadata.name"]; got != (Value{TypeString, "app"}) {
+		t.Errorf("Expected YAML strings to stay strings, got %#v", got)
+	}
+}
+
+func TestEmbeddedParent(t *testing.T) {
+	embedded := map[string]string{"data": "yaml", "data.app": "json"}
+	cases := map[string]string{
+		"data.app.port": "data.app",
+		"data.x":        "data",
+		"data.list[0]":  "data",
+		"database.port": "",
+		"data":          "",
+	}
+	for key, expected := range cases {
+		if got := EmbeddedParent(embedded, key); got != expected {
+			t.Errorf("EmbeddedParent(%q): expected %q, got %q", key, expected, got)
+		}
+	}
+}
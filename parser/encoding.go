@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encodings reported in Result.Encoding.
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF8BOM = "utf-8-bom"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+	EncodingLatin1  = "latin-1"
+	// EncodingBinary marks data that is not text; it is parsed unchanged.
+	EncodingBinary = "binary"
+)
+
+// RawParser is implemented by parsers that want a file's bytes exactly as
+// stored, such as plugins for binary formats, rather than transcoded to UTF-8.
+type RawParser interface {
+	Parser
+	// Raw reports whether the parser reads untranscoded input.
+	Raw() bool
+}
+
+// parserInput returns the input p reads: data as stored when p is a
+// RawParser, or text transcoded from it otherwise.
+func parserInput(p Parser, data, text []byte) []byte {
+	if rp, ok := p.(RawParser); ok && rp.Raw() {
+		return data
+	}
+	return text
+}
+
+// decodeText returns data as UTF-8 along with its original encoding. A byte
+// order mark decides when present; otherwise ASCII-heavy UTF-16 is
+// recognised by its zero bytes, valid UTF-8 is kept, and anything else is
+// read as Latin-1, the usual "ANSI" of Windows tools.
+func decodeText(data []byte) ([]byte, string) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return data[3:], EncodingUTF8BOM
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return decodeUTF16(data[2:], binary.LittleEndian), EncodingUTF16LE
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return decodeUTF16(data[2:], binary.BigEndian), EncodingUTF16BE
+	}
+
+	switch guessUTF16(data) {
+	case EncodingUTF16LE:
+		return decodeUTF16(data, binary.LittleEndian), EncodingUTF16LE
+	case EncodingUTF16BE:
+		return decodeUTF16(data, binary.BigEndian), EncodingUTF16BE
+	}
+	if isBinary(data) {
+		return data, EncodingBinary
+	}
+	if utf8.Valid(data) {
+		return data, EncodingUTF8
+	}
+	return decodeLatin1(data), EncodingLatin1
+}
+
+// guessUTF16 recognises UTF-16 without a byte order mark and returns its
+// encoding, or "". At least half of the leading code units must be ASCII
+// characters, whose high byte is zero, and none may be NUL.
+func guessUTF16(data []byte) string {
+	if len(data) < 4 || len(data)%2 != 0 {
+		return ""
+	}
+	sample := data
+	if len(sample) > 2048 {
+		sample = sample[:2048]
+	}
+	var le, be int
+	for i := 0; i+1 < len(sample); i += 2 {
+		lo, hi := sample[i], sample[i+1]
+		switch {
+		case lo == 0 && hi == 0:
+			return ""
+		case hi == 0:
+			le++
+		case lo == 0:
+			be++
+		}
+	}
+	units := len(sample) / 2
+	switch {
+	case le*2 >= units && be*4 < le:
+		return EncodingUTF16LE
+	case be*2 >= units && le*4 < be:
+		return EncodingUTF16BE
+	}
+	return ""
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) []byte {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	var b strings.Builder
+	for _, r := range utf16.Decode(units) {
+		b.WriteRune(r)
+	}
+	return []byte(b.String())
+}
+
+// decodeLatin1 maps each byte to the code point of the same value, which is
+// exactly ISO-8859-1.
+func decodeLatin1(data []byte) []byte {
+	var b strings.Builder
+	b.Grow(len(data) * 2)
+	for _, c := range data {
+		b.WriteRune(rune(c))
+	}
+	return []byte(b.String())
+}
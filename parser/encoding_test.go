@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// utf16Bytes encodes s as UTF-16, little or big endian.
+func utf16Bytes(s string, bigEndian bool) []byte {
+	var b bytes.Buffer
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			b.Write([]byte{byte(u >> 8), byte(u)})
+		} else {
+			b.Write([]byte{byte(u), byte(u >> 8)})
+		}
+	}
+	return b.Bytes()
+}
+
+func TestDecodeText(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     []byte
+		text     string
+		encoding string
+	}{
+		{"plain", []byte("a=1\n"), "a=1\n", EncodingUTF8},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, "{\"a\":1}"...), "{\"a\":1}", EncodingUTF8BOM},
+		{"utf-16le bom", append([]byte{0xFF, 0xFE}, utf16Bytes("[s]\nk=é\n", false)...), "[s]\nk=é\n", EncodingUTF16LE},
+		{"utf-16be bom", append([]byte{0xFE, 0xFF}, utf16Bytes("k=v", true)...), "k=v", EncodingUTF16BE},
+		{"utf-16le no bom", utf16Bytes("key=value\n", false), "key=value\n", EncodingUTF16LE},
+		{"utf-16be no bom", utf16Bytes("key=value\n", true), "key=value\n", EncodingUTF16BE},
+		{"latin-1", []byte("name=Jos\xe9\n"), "name=José\n", EncodingLatin1},
+		{"binary", []byte{0x7f, 'E', 'L', 'F', 0, 0, 0, 0, 1}, "\x7fELF\x00\x00\x00\x00\x01", EncodingBinary},
+	}
+	for _, tc := range cases {
+		text, encoding := decodeText(tc.data)
+		if string(text) != tc.text || encoding != tc.encoding {
+			t.Errorf("%s: expected %q (%s), got %q (%s)", tc.name, tc.text, tc.encoding, text, encoding)
+		}
+	}
+}
+
+func TestParse_Encodings(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name     string
+		data     []byte
+		encoding string
+		expected map[string]interface{}
+	}{
+		{"bom.json", append([]byte{0xEF, 0xBB, 0xBF}, `{"server": {"port": 80}}`...), EncodingUTF8BOM, map[string]interface{}{"server.port": float64(80)}},
+		{"notepad.ini", append([]byte{0xFF, 0xFE}, utf16Bytes("[app]\r\nname=Grüße\r\n", false)...), EncodingUTF16LE, map[string]interface{}{"app.name": "Grüße"}},
+		{"web.config", append([]byte{0xFF, 0xFE}, utf16Bytes(`<?xml version="1.0" encoding="utf-16"?><configuration><appSettings><add key="a" value="b"/></appSettings></configuration>`, false)...), EncodingUTF16LE, map[string]interface{}{"configuration.appSettings.add.@key": "a"}},
+		{"export.reg", append([]byte{0xFF, 0xFE}, utf16Bytes("Windows Registry Editor Version 5.00\r\n\r\n[HKEY_CURRENT_USER\\Software\\App]\r\n\"Theme\"=\"dark\"\r\n", false)...), EncodingUTF16LE, map[string]interface{}{"HKEY_CURRENT_USER\\Software\\App.Theme": "dark"}},
+		{"legacy.txt", []byte("city=K\xf6ln\n"), EncodingLatin1, map[string]interface{}{"city": "Köln"}},
+	}
+	for _, tc := range cases {
+		file := filepath.Join(dir, tc.name)
+		os.WriteFile(file, tc.data, 0644)
+		result, err := Parse(file, Options{})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if result.Encoding != tc.encoding {
+			t.Errorf("%s: expected encoding %s, got %s", tc.name, tc.encoding, result.Encoding)
+		}
+		for k, v := range tc.expected {
+			if result.Settings[k] != v {
+				t.Errorf("%s: expected %s=%#v, got %#v", tc.name, k, v, result.Settings[k])
+			}
+		}
+	}
+}
+
+func TestParseData_Encoding(t *testing.T) {
+	result := ParseData(append([]byte{0xFF, 0xFE}, utf16Bytes(`{"a": "b"}`, false)...), Options{})
+	if result.Format != "json" || result.Encoding != EncodingUTF16LE || result.Settings["a"] != "b" {
+		t.Errorf("Expected UTF-16 JSON to parse, got %s %s %v", result.Format, result.Encoding, result.Settings)
+	}
+}
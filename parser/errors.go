@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// ParseError describes a file that could not be parsed. Parsers may return
+// one with Line and Column set; Parse fills in Format, File and Snippet and
+// derives positions from the errors of the underlying decoders.
+type ParseError struct {
+	Format  string `json:"format"`
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Snippet string `json:"snippet,omitempty"`
+	Message string `json:"message"`
+	Err     error  `json:"-"`
+}
+
+func (e *ParseError) Error() string {
+	loc := e.File
+	if e.Line > 0 {
+		loc += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			loc += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	if loc == "" {
+		return e.Format + ": " + e.Message
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, e.Format, e.Message)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// newParseError wraps err from parsing data as format into a *ParseError,
+// locating the failure where the decoder reports it.
+func newParseError(format, file string, data []byte, err error) *ParseError {
+	pe := &ParseError{Format: format, File: file, Message: err.Error(), Err: err}
+
+	var parsed *ParseError
+	var jsonSyntax *json.SyntaxError
+	var jsonType *json.UnmarshalTypeError
+	var xmlSyntax *xml.SyntaxError
+	var tomlErr toml.ParseError
+	switch {
+	case errors.As(err, &parsed):
+		pe.Line, pe.Column, pe.Message = parsed.Line, parsed.Column, parsed.Message
+		if parsed.Err != nil {
+			pe.Err = parsed.Err
+		}
+	case errors.As(err, &jsonSyntax):
+		pe.Line, pe.Column = offsetPosition(data, jsonSyntax.Offset)
+	case errors.As(err, &jsonType):
+		pe.Line, pe.Column = offsetPosition(data, jsonType.Offset)
+	case errors.As(err, &xmlSyntax):
+		pe.Line, pe.Message = xmlSyntax.Line, xmlSyntax.Msg
+	case errors.As(err, &tomlErr):
+		pe.Line, pe.Column, pe.Message = tomlErr.Position.Line, tomlErr.Position.Col, tomlErr.Message
+	case format == "yaml" && strings.HasPrefix(pe.Message, "yaml: "):
+		if m := yamlErrorLine.FindStringSubmatch(pe.Message); m != nil {
+			pe.Line, _ = strconv.Atoi(m[1])
+			pe.Message = strings.TrimPrefix(pe.Message, "yaml: "+m[0]+": ")
+		}
+	}
+
+	if pe.Line > 0 {
+		lines := strings.Split(string(data), "\n")
+		if pe.Line <= len(lines) {
+			pe.Snippet = strings.TrimRight(lines[pe.Line-1], "\r")
+			if len(pe.Snippet) > 120 {
+				pe.Snippet = pe.Snippet[:120] + "..."
+			}
+		}
+	}
+	return pe
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column.
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := string(data[:offset])
+	line := strings.Count(before, "\n") + 1
+	col := len(before) - strings.LastIndex(before, "\n")
+	return line, col
+}
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse_StructuredErrors(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name, content string
+		line, column  int
+		snippet       string
+	}{
+		{"broken.json", "{\n  \"a\": 1,,\n}", 2, 11, `  "a": 1,,`},
+		{"broken.toml", "[server]\nport = = 1\n", 2, 0, "port = = 1"},
+		{"broken.xml", "<a>\n<b></a>\n", 2, 0, "<b></a>"},
+		{"broken.tf", "job \"x\" {\n  a = 1\n", 3, 1, ""},
+	}
+	for _, tc := range cases {
+		file := filepath.Join(dir, tc.name)
+		os.WriteFile(file, []byte(tc.content), 0644)
+		result, err := Parse(file, Options{})
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%s: expected *ParseError, got %v", tc.name, err)
+			continue
+		}
+		if result.Settings != nil {
+			t.Errorf("%s: expected no settings, got %v", tc.name, result.Settings)
+		}
+		if pe.File != file || pe.Format != result.Format || pe.Line != tc.line || (tc.column > 0 && pe.Column != tc.column) {
+			t.Errorf("%s: unexpected error location %+v", tc.name, pe)
+		}
+		if pe.Snippet != tc.snippet {
+			t.Errorf("%s: expected snippet %q, got %q", tc.name, tc.snippet, pe.Snippet)
+		}
+	}
+}
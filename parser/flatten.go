@@ -1,5 +1,101 @@
 package parser
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ArrayNotation controls how list indexes appear in flattened keys.
+type ArrayNotation int
+
+const (
+	// BracketNotation renders list elements as servers[0].host.
+	BracketNotation ArrayNotation = iota
+	// DotNotation renders list elements as servers.0.host.
+	DotNotation
+)
+
+// ParseArrayNotation maps "bracket" or "dot" to an ArrayNotation.
+func ParseArrayNotation(s string) (ArrayNotation, error) {
+	switch strings.ToLower(s) {
+	case "", "bracket":
+		return BracketNotation, nil
+	case "dot":
+		return DotNotation, nil
+	}
+	return BracketNotation, fmt.Errorf("unknown array notation %q (use bracket or dot)", s)
+}
+
+// indexKey appends list index i to key. Parsers always use bracket
+// notation; Parse rewrites the keys for DotNotation afterwards.
+func indexKey(key string, i int) string {
+	return fmt.Sprintf("%s[%d]", key, i)
+}
+
+var bracketIndex = regexp.MustCompile(`\[(\d+)\]`)
+
+// dotIndexes rewrites the list indexes of key from servers[0].host to
+// servers.0.host.
+func dotIndexes(key string) string {
+	dotted := bracketIndex.ReplaceAllString(key, ".$1")
+	if strings.HasPrefix(key, "[") {
+		dotted = dotted[1:]
+	}
+	return dotted
+}
+
+// useDotIndexes rewrites every key of result with dotIndexes.
+func useDotIndexes(result *Result) {
+	result.Settings = renameKeys(result.Settings)
+	result.Positions = renameKeys(result.Positions)
+	result.Comments = renameKeys(result.Comments)
+	result.Normalized = renameKeys(result.Normalized)
+	result.Embedded = renameKeys(result.Embedded)
+	for i, k := range result.Keys {
+		result.Keys[i] = dotIndexes(k)
+	}
+}
+
+func renameKeys[V any](m map[string]V) map[string]V {
+	if m == nil {
+		return nil
+	}
+	renamed := make(map[string]V, len(m))
+	for k, v := range m {
+		renamed[dotIndexes(k)] = v
+	}
+	return renamed
+}
+
+// RootKey holds the value of a document whose root is a scalar rather than
+// an object or list.
+const RootKey = "root"
+
+// flattenRoot flattens a decoded document of any shape: objects as usual,
+// lists with indexed keys ([0].name), and scalars under RootKey.
+func flattenRoot(v interface{}) map[string]interface{} {
+	switch root := v.(type) {
+	case nil:
+		return make(map[string]interface{})
+	case map[string]interface{}:
+		return flatten(root, "")
+	case []interface{}:
+		if len(root) > 0 {
+			flat := make(map[string]interface{})
+			flattenValue(root, "", flat)
+			return flat
+		}
+	}
+	return map[string]interface{}{RootKey: v}
+}
+
+// isScalarRoot reports whether flat came from a document with a scalar root.
+func isScalarRoot(flat map[string]interface{}) bool {
+	_, ok := flat[RootKey]
+	return ok && len(flat) == 1
+}
+
 func flatten(data map[string]interface{}, prefix string) map[string]interface{} {
 	flat := make(map[string]interface{})
 
@@ -8,16 +104,37 @@ func flatten(data map[string]interface{}, prefix string) map[string]interface{}
 		if prefix != "" {
 			key = prefix + "." + k
 		}
+		flattenValue(v, key, flat)
+	}
+	return flat
+}
 
-		switch sub := v.(type) {
-		case map[string]interface{}:
-			nested := flatten(sub, key)
-			for nk, nv := range nested {
-				flat[nk] = nv
-			}
-		default:
-			flat[key] = v
+// flattenValue stores v under key, descending into maps and lists.
+// Empty maps and lists are kept as values so their keys stay visible.
+func flattenValue(v interface{}, key string, flat map[string]interface{}) {
+	switch sub := v.(type) {
+	case map[string]interface{}:
+		if len(sub) == 0 {
+			flat[key] = sub
+			return
+		}
+		for nk, nv := range flatten(sub, key) {
+			flat[nk] = nv
+		}
+	case []interface{}:
+		if len(sub) == 0 {
+			flat[key] = sub
+			return
 		}
+		for i, item := range sub {
+			flattenValue(item, indexKey(key, i), flat)
+		}
+	case []map[string]interface{}:
+		// TOML arrays of tables
+		for i, m := range sub {
+			flattenValue(m, indexKey(key, i), flat)
+		}
+	default:
+		flat[key] = v
 	}
-	return flat
-}
\ No newline at end of file
+}
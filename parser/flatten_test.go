@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFlatten_Arrays(t *testing.T) {
+	data := map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a", "ports": []interface{}{80, 443}},
+			map[string]interface{}{"host": "b"},
+		},
+		"empty": []interface{}{},
+	}
+
+	flat := flatten(data, "")
+	for k, v := range map[string]interface{}{"servers[0].host": "a", "servers[0].ports[1]": 443, "servers[1].host": "b"} {
+		if flat[k] != v {
+			t.Errorf("Expected %s = %v, got %v", k, v, flat[k])
+		}
+	}
+	if _, ok := flat["empty"]; !ok {
+		t.Errorf("Expected empty list to keep its key, got %v", flat)
+	}
+}
+
+func TestParse_IndexNotation(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.yaml")
+	os.WriteFile(file, []byte("servers:\n  - host: a\n    ports: [80, 443]\n  - host: b\n"), 0644)
+
+	cases := []struct {
+		notation ArrayNotation
+		expected []string
+	}{
+		{BracketNotation, []string{"servers[0].host", "servers[0].ports[0]", "servers[0].ports[1]", "servers[1].host"}},
+		{DotNotation, []string{"servers.0.host", "servers.0.ports.0", "servers.0.ports.1", "servers.1.host"}},
+	}
+	for _, tc := range cases {
+		result, err := Parse(file, Options{IndexNotation: tc.notation})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Keys) != len(tc.expected) {
+			t.Fatalf("Expected keys %v, got %v", tc.expected, result.Keys)
+		}
+		for i, k := range tc.expected {
+			if result.Keys[i] != k {
+				t.Errorf("Expected key %d to be %s, got %s", i, k, result.Keys[i])
+			}
+			if _, ok := result.Settings[k]; !ok {
+				t.Errorf("Expected setting %s, got %v", k, result.Settings)
+			}
+			if _, ok := result.Positions[k]; !ok {
+				t.Errorf("Expected position of %s, got %v", k, result.Positions)
+			}
+		}
+	}
+
+	result := ParseData([]byte(`[{"name": "a"}]`), Options{IndexNotation: DotNotation})
+	if result.Settings["0.name"] != "a" {
+		t.Errorf("Expected a list root to flatten to 0.name, got %v", result.Settings)
+	}
+}
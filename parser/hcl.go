@@ -0,0 +1,529 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hclBlockHeader = regexp.MustCompile(`^[A-Za-z_][\w-]*(\s+("[^"]*"|[A-Za-z_][\w-]*))*\s*\{\s*$`)
+
+func ParseHCL(path string) map[string]interface{} {
+	return parsePath(hclParser{}, path)
+}
+
+type hclParser struct{}
+
+func (hclParser) Name() string { return "hcl" }
+
+func (hclParser) Patterns() []string { return []string{".tf", ".tfvars", ".hcl", ".nomad"} }
+
+func (hclParser) Sniff(data []byte) float64 {
+	if looksLikeHCL(string(data)) {
+		return 0.7
+	}
+	return 0
+}
+
+func (p hclParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	settings, _, err := p.ParsePositions(r)
+	return settings, err
+}
+
+func (hclParser) ParsePositions(r io.Reader) (map[string]interface{}, map[string]Position, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseHCL(string(data))
+}
+
+// parseHCL flattens HCL (Terraform, Nomad, Consul, Vault, .tfvars). Blocks
+// nest under their type and labels (resource.aws_instance.web), repeated
+// blocks become lists, and literal strings, numbers, bools, tuples, objects
+// and heredocs are decoded. Any expression that would need evaluating
+// (references, function calls, operators, for expressions) is kept as its
+// source text. Each setting is positioned at its attribute name, and an
+// empty block at its header.
+func parseHCL(text string) (map[string]interface{}, map[string]Position, error) {
+	src := strings.ReplaceAll(text, "\r\n", "\n")
+	p := &hclScanner{src: src, lines: newLineIndex([]byte(src))}
+	body, positions, err := p.parseBody(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings := flatten(body, "")
+	return settings, keepPositions(positions, settings), nil
+}
+
+// looksLikeHCL reports whether text contains a block header such as
+// `resource "aws_instance" "web" {`.
+func looksLikeHCL(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if hclBlockHeader.MatchString(strings.TrimSpace(line)) {
+			return true
+		}
+	}
+	return false
+}
+
+type hclScanner struct {
+	src   string
+	pos   int
+	lines lineIndex // of src, for positions; nil when scanning an expression
+}
+
+func (p *hclScanner) errorf(format string, args ...interface{}) error {
+	at := p.position(p.pos)
+	return &ParseError{Format: "hcl", Line: at.Line, Column: at.Column, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *hclScanner) position(offset int) Position {
+	return p.lines.position(int64(offset))
+}
+
+// parseBody reads attributes and blocks up to the end of the input, or the
+// closing } when nested. Positions are keyed by flattened key relative to
+// the body; "" is where the body's block starts.
+func (p *hclScanner) parseBody(nested bool) (map[string]interface{}, map[string]Position, error) {
+	body := make(map[string]interface{})
+	positions := make(map[string]Position)
+	// positions of each block, by its path, resolved once repeats are known
+	blocks := make(map[string][]map[string]Position)
+	for {
+		p.skipSpace(true)
+		if p.pos >= len(p.src) {
+			if nested {
+				return nil, nil, p.errorf("unexpected end of input, missing }")
+			}
+			return body, resolveHCLBlocks(positions, blocks), nil
+		}
+		if p.src[p.pos] == '}' {
+			if !nested {
+				return nil, nil, p.errorf("unexpected }")
+			}
+			p.pos++
+			return body, resolveHCLBlocks(positions, blocks), nil
+		}
+
+		start := p.pos
+		name := p.ident()
+		if name == "" {
+			return nil, nil, p.errorf("unexpected %q", p.src[p.pos])
+		}
+		p.skipSpace(false)
+
+		if strings.HasPrefix(p.src[p.pos:], "=") && !strings.HasPrefix(p.src[p.pos:], "==") {
+			p.pos++
+			p.skipSpace(false)
+			end, closed := hclExprEnd(p.src, p.pos)
+			if !closed {
+				return nil, nil, p.errorf("unexpected end of input, unclosed bracket in %s", name)
+			}
+			body[name] = evalHCLExpr(p.src[p.pos:end])
+			// elements of a tuple or object share the attribute's position
+			inner := make(map[string]interface{})
+			flattenValue(body[name], name, inner)
+			for k := range inner {
+				positions[k] = p.position(start)
+			}
+			p.pos = end
+			continue
+		}
+
+		path := []string{name}
+		for {
+			p.skipSpace(false)
+			if p.pos >= len(p.src) {
+				return nil, nil, p.errorf("unexpected end of input in block %s", name)
+			}
+			if p.src[p.pos] == '{' {
+				p.pos++
+				break
+			}
+			if p.src[p.pos] == '"' {
+				end := hclStringEnd(p.src, p.pos)
+				path = append(path, hclUnquote(p.src[p.pos:end]))
+				p.pos = end
+				continue
+			}
+			label := p.ident()
+			if label == "" {
+				return nil, nil, p.errorf("expected block label or { after %s", name)
+			}
+			path = append(path, label)
+		}
+		child, childPositions, err := p.parseBody(true)
+		if err != nil {
+			return nil, nil, err
+		}
+		childPositions[""] = p.position(start)
+		insertHCLBlock(body, path, child)
+		key := strings.Join(path, ".")
+		blocks[key] = append(blocks[key], childPositions)
+	}
+}
+
+// resolveHCLBlocks adds the positions of blocks to those of a body's
+// attributes, indexing a repeated block like the list insertHCLBlock makes
+// of it.
+func resolveHCLBlocks(positions map[string]Position, blocks map[string][]map[string]Position) map[string]Position {
+	for key, list := range blocks {
+		for i, child := range list {
+			prefix := key
+			if len(list) > 1 {
+				prefix = indexKey(key, i)
+			}
+			for k, at := range child {
+				if k == "" {
+					positions[prefix] = at
+				} else {
+					positions[joinKey(prefix, k)] = at
+				}
+			}
+		}
+	}
+	return positions
+}
+
+// insertHCLBlock stores child at the nested path, turning repeated blocks
+// into a list.
+func insertHCLBlock(body map[string]interface{}, path []string, child map[string]interface{}) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := body[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			body[key] = next
+		}
+		body = next
+	}
+	last := path[len(path)-1]
+	switch existing := body[last].(type) {
+	case nil:
+		body[last] = child
+	case []interface{}:
+		body[last] = append(existing, child)
+	default:
+		body[last] = []interface{}{existing, child}
+	}
+}
+
+func (p *hclScanner) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if isIdentStart(c) || (p.pos > start && (c >= '0' && c <= '9' || c == '-')) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+// skipSpace skips blanks and comments, and newlines too when newlines is set.
+func (p *hclScanner) skipSpace(newlines bool) {
+	for p.pos < len(p.src) {
+		rest := p.src[p.pos:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r':
+			p.pos++
+		case rest[0] == '\n' && newlines:
+			p.pos++
+		case rest[0] == '#' || strings.HasPrefix(rest, "//"):
+			if i := strings.IndexByte(rest, '\n'); i >= 0 {
+				p.pos += i
+			} else {
+				p.pos = len(p.src)
+			}
+		case strings.HasPrefix(rest, "/*"):
+			if i := strings.Index(rest[2:], "*/"); i >= 0 {
+				p.pos += i + 4
+			} else {
+				p.pos = len(p.src)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// hclExprEnd returns the end of the expression starting at i: the first
+// newline, comma or unbalanced closing bracket outside any nesting, with
+// trailing blanks and comments excluded. It reports false when the input
+// ends inside a bracket the expression opened.
+func hclExprEnd(src string, i int) (int, bool) {
+	depth := 0
+	last := i
+	for i < len(src) {
+		c := src[i]
+		rest := src[i:]
+		switch {
+		case c == '"':
+			i = hclStringEnd(src, i)
+			last = i
+			continue
+		case strings.HasPrefix(rest, "<<"):
+			if end := hclHeredocEnd(src, i); end > i {
+				i = end
+				last = i
+				continue
+			}
+		case c == '#' || strings.HasPrefix(rest, "//"):
+			if j := strings.IndexByte(rest, '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(src)
+			}
+			continue
+		case strings.HasPrefix(rest, "/*"):
+			if j := strings.Index(rest[2:], "*/"); j >= 0 {
+				i += j + 4
+			} else {
+				i = len(src)
+			}
+			continue
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			if depth == 0 {
+				return last, true
+			}
+			depth--
+		case (c == ',' || c == '\n') && depth == 0:
+			return last, true
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			last = i + 1
+		}
+		i++
+	}
+	return last, depth == 0
+}
+
+// hclStringEnd returns the index just past the quoted template starting at
+// i, skipping over ${ } and %{ } interpolations.
+func hclStringEnd(src string, i int) int {
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1
+		case '\n':
+			return j
+		case '$', '%':
+			if j+1 < len(src) && src[j+1] == '{' && src[j-1] != src[j] {
+				j = hclBracketEnd(src, j+1) - 1
+			}
+		}
+	}
+	return len(src)
+}
+
+// hclBracketEnd returns the index just past the bracket matching the one
+// at i.
+func hclBracketEnd(src string, i int) int {
+	depth := 0
+	for j := i; j < len(src); j++ {
+		switch src[j] {
+		case '"':
+			j = hclStringEnd(src, j) - 1
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(src)
+}
+
+// hclEnclosed reports whether raw is one bracketed expression, from its
+// opening bracket to the matching close.
+func hclEnclosed(raw string, closer byte) bool {
+	return len(raw) >= 2 && raw[len(raw)-1] == closer && hclBracketEnd(raw, 0) == len(raw)
+}
+
+// hclHeredocEnd returns the index just past the closing marker of a
+// <<EOF or <<-EOF heredoc starting at i, or i if there is none.
+func hclHeredocEnd(src string, i int) int {
+	marker, bodyStart, ok := hclHeredocMarker(src[i:])
+	if !ok {
+		return i
+	}
+	pos := i + bodyStart
+	for pos < len(src) {
+		end := strings.IndexByte(src[pos:], '\n')
+		if end < 0 {
+			end = len(src) - pos
+		}
+		if strings.TrimSpace(src[pos:pos+end]) == marker {
+			return pos + end
+		}
+		pos += end + 1
+	}
+	return len(src)
+}
+
+// hclHeredocMarker parses "<<EOF\n" or "<<-EOF\n", returning the marker and
+// the offset at which the body starts.
+func hclHeredocMarker(s string) (string, int, bool) {
+	j := 2
+	if j < len(s) && s[j] == '-' {
+		j++
+	}
+	start := j
+	for j < len(s) && isIdentPart(s[j]) {
+		j++
+	}
+	if j == start || j >= len(s) || s[j] != '\n' {
+		return "", 0, false
+	}
+	return s[start:j], j + 1, true
+}
+
+func evalHCLExpr(raw string) interface{} {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case raw == "":
+		return ""
+	case raw == "true":
+		return true
+	case raw == "false":
+		return false
+	case raw == "null":
+		return nil
+	case raw[0] == '"' && hclStringEnd(raw, 0) == len(raw):
+		return hclUnquote(raw)
+	case strings.HasPrefix(raw, "<<") && hclHeredocEnd(raw, 0) == len(raw):
+		return hclHeredocBody(raw)
+	case raw[0] == '[' && hclEnclosed(raw, ']'):
+		if list, ok := evalHCLTuple(raw[1 : len(raw)-1]); ok {
+			return list
+		}
+	case raw[0] == '{' && hclEnclosed(raw, '}'):
+		if obj, ok := evalHCLObject(raw[1 : len(raw)-1]); ok {
+			return obj
+		}
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return int(n)
+	}
+	if f, err := strconv.ParseFloat(raw, 64); err == nil {
+		return f
+	}
+	return raw
+}
+
+func evalHCLTuple(inner string) ([]interface{}, bool) {
+	if strings.HasPrefix(strings.TrimSpace(inner), "for ") {
+		return nil, false
+	}
+	list := make([]interface{}, 0)
+	p := &hclScanner{src: inner}
+	for {
+		p.skipSpace(true)
+		if p.pos >= len(p.src) {
+			return list, true
+		}
+		if p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		end, _ := hclExprEnd(p.src, p.pos)
+		if end == p.pos {
+			return nil, false
+		}
+		list = append(list, evalHCLExpr(p.src[p.pos:end]))
+		p.pos = end
+	}
+}
+
+func evalHCLObject(inner string) (map[string]interface{}, bool) {
+	if strings.HasPrefix(strings.TrimSpace(inner), "for ") {
+		return nil, false
+	}
+	obj := make(map[string]interface{})
+	p := &hclScanner{src: inner}
+	for {
+		p.skipSpace(true)
+		if p.pos >= len(p.src) {
+			return obj, true
+		}
+		if p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+
+		var key string
+		switch p.src[p.pos] {
+		case '"':
+			end := hclStringEnd(p.src, p.pos)
+			key = hclUnquote(p.src[p.pos:end])
+			p.pos = end
+		case '(':
+			end := hclBracketEnd(p.src, p.pos)
+			key = p.src[p.pos:end]
+			p.pos = end
+		default:
+			start := p.pos
+			for p.pos < len(p.src) && !strings.ContainsRune(" \t\n=:", rune(p.src[p.pos])) {
+				p.pos++
+			}
+			key = p.src[start:p.pos]
+		}
+		p.skipSpace(false)
+		if p.pos >= len(p.src) || (p.src[p.pos] != '=' && p.src[p.pos] != ':') {
+			return nil, false
+		}
+		p.pos++
+		p.skipSpace(false)
+		end, _ := hclExprEnd(p.src, p.pos)
+		obj[key] = evalHCLExpr(p.src[p.pos:end])
+		p.pos = end
+	}
+}
+
+// hclUnquote decodes a quoted string. Templates with interpolations are kept
+// as written, minus the quotes.
+func hclUnquote(quoted string) string {
+	body := strings.TrimSuffix(strings.TrimPrefix(quoted, `"`), `"`)
+	if strings.Contains(body, "${") || strings.Contains(body, "%{") {
+		return body
+	}
+	if s, err := strconv.Unquote(`"` + body + `"`); err == nil {
+		return s
+	}
+	return body
+}
+
+// hclHeredocBody returns the text of a heredoc, with common indentation
+// removed for the <<- form.
+func hclHeredocBody(raw string) string {
+	_, bodyStart, _ := hclHeredocMarker(raw)
+	lines := strings.Split(raw[bodyStart:], "\n")
+	lines = lines[:len(lines)-1] // closing marker
+	if strings.HasPrefix(raw, "<<-") {
+		indent := -1
+		for _, l := range lines {
+			if strings.TrimSpace(l) == "" {
+				continue
+			}
+			n := len(l) - len(strings.TrimLeft(l, " \t"))
+			if indent < 0 || n < indent {
+				indent = n
+			}
+		}
+		for i, l := range lines {
+			if len(l) >= indent && indent > 0 {
+				lines[i] = l[indent:]
+			}
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseHCL_Terraform(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.tf")
+	os.WriteFile(file, []byte(`# provider
+provider "aws" {
+  region = "us-east-1"
+}
+
+resource "aws_instance" "web" {
+  ami           = data.aws_ami.ubuntu.id
+  instance_type = "t3.micro" // cheap
+  count         = 2
+  monitoring    = true
+  tags = {
+    Name = "web-${var.env}"
+    "Team" = "ops"
+  }
+  security_groups = ["sg-1", var.extra_sg]
+
+  ebs_block_device {
+    device_name = "/dev/sdg"
+  }
+  ebs_block_device {
+    device_name = "/dev/sdh"
+  }
+
+  user_data = <<-EOT
+    #!/bin/bash
+    echo hi
+  EOT
+}
+
+locals { zones = [for z in var.zones : upper(z)] }
+`), 0644)
+
+	result, format := ParseFile(file)
+	if format != "hcl" {
+		t.Errorf("Expected format hcl, got %s", format)
+	}
+	expected := map[string]interface{}{
+		"provider.aws.region":                                       "us-east-1",
+		"resource.aws_instance.web.ami":                             "data.aws_ami.ubuntu.id",
+		"resource.aws_instance.web.instance_type":                   "t3.micro",
+		"resource.aws_instance.web.count":                           2,
+		"resource.aws_instance.web.monitoring":                      true,
+		"resource.aws_instance.web.tags.Name":                       "web-${var.env}",
+		"resource.aws_instance.web.tags.Team":                       "ops",
+		"resource.aws_instance.web.security_groups[1]":              "var.extra_sg",
+		"resource.aws_instance.web.ebs_block_device[1].device_name": "/dev/sdh",
+		"resource.aws_instance.web.user_data":                       "#!/bin/bash\necho hi\n",
+		"locals.zones":                                              "[for z in var.zones : upper(z)]",
+	}
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("Expected %s = %#v, got %#v", k, v, result[k])
+		}
+	}
+}
+
+func TestParseHCL_Unterminated(t *testing.T) {
+	if _, _, err := parseHCL("job \"x\" {\n  a = 1\n"); err == nil {
+		t.Errorf("Expected error for unterminated block")
+	}
+	for _, src := range []string{"tags = [", "x = {", "tags = [\n  \"a\",", "tags = [\n  \"a\",\n"} {
+		_, _, err := parseHCL(src)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: expected a *ParseError for the unclosed bracket, got %v", src, err)
+		}
+	}
+}
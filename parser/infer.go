@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Value types reported by Normalize.
+const (
+	TypeString   = "string"
+	TypeBool     = "bool"
+	TypeInt      = "int"
+	TypeFloat    = "float"
+	TypeDuration = "duration"
+	TypeSize     = "size"
+	TypeTime     = "time"
+	TypeNull     = "null"
+	TypeList     = "list"
+	TypeMap      = "map"
+)
+
+// Value is a setting value in a representation shared by every format, so
+// that 8080 in JSON and "8080" in an INI file compare equal.
+type Value struct {
+	// Type is one of the Type constants.
+	Type string `json:"type"`
+	// Value is a bool, int64, float64 or string; durations are in
+	// time.Duration notation ("1m30s"), sizes a number of bytes, and times
+	// RFC 3339.
+	Value interface{} `json:"value"`
+}
+
+// String formats the normalized value.
+func (v Value) String() string {
+	return fmt.Sprintf("%v", v.Value)
+}
+
+// UntypedParser is implemented by parsers of formats without value types,
+// whose settings are all strings. Normalize infers types for them.
+type UntypedParser interface {
+	Parser
+	// Untyped reports whether every value returned by Parse is text.
+	Untyped() bool
+}
+
+// isUntyped reports whether p only ever returns strings.
+func isUntyped(p Parser) bool {
+	u, ok := p.(UntypedParser)
+	return ok && u.Untyped()
+}
+
+// normalizeSettings normalizes every setting, inferring types from strings
+// of untyped formats: the file's own, or for settings expanded from an
+// embedded document, that document's.
+func normalizeSettings(settings map[string]interface{}, untyped bool, embedded map[string]string) map[string]Value {
+	normalized := make(map[string]Value, len(settings))
+	for k, v := range settings {
+		infer := untyped
+		if parent := EmbeddedParent(embedded, k); parent != "" {
+			infer = isUntyped(Lookup(strings.TrimPrefix(embedded[parent], "base64+")))
+		}
+		normalized[k] = Normalize(v, infer)
+	}
+	return normalized
+}
+
+// Normalize converts a parsed value to a Value. Whole floats become ints, as
+// JSON has no separate integer type. With infer set, strings are read as the
+// first type they fit:
+//
+//   - bool: true/false, yes/no, on/off, in any case
+//   - int: decimal digits without leading zeros, so 0644 stays a string
+//   - float: decimal notation with an optional exponent
+//   - duration: Go duration syntax such as 250ms, 30s or 1h30m
+//   - size: a number with a byte unit; KB/MB/GB/TB are powers of 1000,
+//     KiB/MiB/GiB/TiB and the bare K/G/T suffixes powers of 1024. A bare
+//     M is minutes, so write 512MB or 512MiB for sizes.
+func Normalize(v interface{}, infer bool) Value {
+	switch x := v.(type) {
+	case nil:
+		return Value{TypeNull, nil}
+	case bool:
+		return Value{TypeBool, x}
+	case int:
+		return Value{TypeInt, int64(x)}
+	case int64:
+		return Value{TypeInt, x}
+	case uint64:
+		if x <= math.MaxInt64 {
+			return Value{TypeInt, int64(x)}
+		}
+		return Value{TypeFloat, float64(x)}
+	case float64:
+		return normalizeFloat(x)
+	case time.Time:
+		return Value{TypeTime, x.Format(time.RFC3339Nano)}
+	case []interface{}, []map[string]interface{}:
+		return Value{TypeList, v}
+	case map[string]interface{}:
+		return Value{TypeMap, v}
+	case string:
+		if infer {
+			return inferValue(x)
+		}
+		return Value{TypeString, x}
+	}
+	return Value{TypeString, fmt.Sprintf("%v", v)}
+}
+
+var (
+	decimalNumber = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+	sizeValue     = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*(b|kb|mb|gb|tb|kib|mib|gib|tib|k|g|t)$`)
+)
+
+var sizeUnits = map[string]float64{
+	"b":  1,
+	"kb": 1e3, "mb": 1e6, "gb": 1e9, "tb": 1e12,
+	"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30, "tib": 1 << 40,
+	"k": 1 << 10, "g": 1 << 30, "t": 1 << 40,
+}
+
+func inferValue(s string) Value {
+	text := strings.TrimSpace(s)
+	switch strings.ToLower(text) {
+	case "true", "yes", "on":
+		return Value{TypeBool, true}
+	case "false", "no", "off":
+		return Value{TypeBool, false}
+	}
+	if decimalNumber.MatchString(text) {
+		digits := strings.TrimLeft(text, "+-")
+		if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+			return Value{TypeString, s}
+		}
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return Value{TypeInt, i}
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return normalizeFloat(f)
+		}
+	}
+	if d, err := time.ParseDuration(text); err == nil {
+		return Value{TypeDuration, d.String()}
+	}
+	if m := sizeValue.FindStringSubmatch(text); m != nil {
+		n, _ := strconv.ParseFloat(m[1], 64)
+		return Value{TypeSize, int64(n * sizeUnits[strings.ToLower(m[2])])}
+	}
+	return Value{TypeString, s}
+}
+
+// normalizeFloat reports whole floats that fit an int64 exactly as ints.
+func normalizeFloat(f float64) Value {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return Value{TypeInt, int64(f)}
+	}
+	return Value{TypeFloat, f}
+}
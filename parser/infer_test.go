@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNormalize_Infer(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected Value
+	}{
+		{"true", Value{TypeBool, true}},
+		{"Yes", Value{TypeBool, true}},
+		{"OFF", Value{TypeBool, false}},
+		{"8080", Value{TypeInt, int64(8080)}},
+		{"-3", Value{TypeInt, int64(-3)}},
+		{"0", Value{TypeInt, int64(0)}},
+		{"0644", Value{TypeString, "0644"}},
+		{"0.75", Value{TypeFloat, 0.75}},
+		{"1.0", Value{TypeInt, int64(1)}},
+		{"1e3", Value{TypeInt, int64(1000)}},
+		{"30s", Value{TypeDuration, "30s"}},
+		{"1h30m", Value{TypeDuration, "1h30m0s"}},
+		{"250ms", Value{TypeDuration, "250ms"}},
+		{"10m", Value{TypeDuration, "10m0s"}},
+		{"512MB", Value{TypeSize, int64(512000000)}},
+		{"512MiB", Value{TypeSize, int64(512 << 20)}},
+		{"2k", Value{TypeSize, int64(2048)}},
+		{"1.5 GB", Value{TypeSize, int64(1500000000)}},
+		{"100b", Value{TypeSize, int64(100)}},
+		{"localhost", Value{TypeString, "localhost"}},
+		{"NaN", Value{TypeString, "NaN"}},
+		{"", Value{TypeString, ""}},
+	}
+	for _, tc := range cases {
+		if got := Normalize(tc.input, true); got != tc.expected {
+			t.Errorf("Normalize(%q): expected %#v, got %#v", tc.input, tc.expected, got)
+		}
+	}
+}
+
+func TestNormalize_Typed(t *testing.T) {
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		input    interface{}
+		expected Value
+	}{
+		{"yes", Value{TypeString, "yes"}},
+		{true, Value{TypeBool, true}},
+		{8080, Value{TypeInt, int64(8080)}},
+		{int64(7), Value{TypeInt, int64(7)}},
+		{float64(8080), Value{TypeInt, int64(8080)}},
+		{0.5, Value{TypeFloat, 0.5}},
+		{nil, Value{TypeNull, nil}},
+		{when, Value{TypeTime, "2024-01-02T03:04:05Z"}},
+	}
+	for _, tc := range cases {
+		if got := Normalize(tc.input, false); got != tc.expected {
+			t.Errorf("Normalize(%#v): expected %#v, got %#v", tc.input, tc.expected, got)
+		}
+	}
+}
+
+func TestParse_InferTypes(t *testing.T) {
+	dir := t.TempDir()
+	ini := filepath.Join(dir, "app.ini")
+	os.WriteFile(ini, []byte("[server]\nport = 8080\ndebug = yes\ntimeout = 30s\n"), 0644)
+	js := filepath.Join(dir, "app.json")
+	os.WriteFile(js, []byte(`{"server": {"port": 8080, "debug": true, "timeout": "30s", "name": "yes"}}`), 0644)
+
+	result, _ := Parse(ini, Options{})
+	if result.Normalized != nil {
+		t.Errorf("Expected no normalized values without InferTypes, got %v", result.Normalized)
+	}
+
+	fromINI, err := Parse(ini, Options{InferTypes: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fromJSON, err := Parse(js, Options{InferTypes: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, k := range []string{"server.port", "server.debug"} {
+		if fromINI.Normalized[k] != fromJSON.Normalized[k] {
+			t.Errorf("Expected %s to normalize alike, got %#v (ini) and %#v (json)", k, fromINI.Normalized[k], fromJSON.Normalized[k])
+		}
+	}
+	if fromINI.Settings["server.port"] != "8080" {
+		t.Errorf("Expected raw INI value to stay a string, got %#v", fromINI.Settings["server.port"])
+	}
+	if got := fromINI.Normalized["server.timeout"]; got != (Value{TypeDuration, "30s"}) {
+		t.Errorf("Expected INI timeout to be a duration, got %#v", got)
+	}
+	// strings in typed formats are left alone
+	if got := fromJSON.Normalized["server.name"]; got != (Value{TypeString, "yes"}) {
+		t.Errorf("Expected JSON string to stay a string, got %#v", got)
+	}
+}
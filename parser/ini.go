@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	iniSection  = regexp.MustCompile(`^\[\s*([^\]"]+?)\s*(?:"([^"]*)")?\s*\]$`)
+	iniBareWord = regexp.MustCompile(`^[\w.\-]+$`)
+)
+
+func ParseINI(path string) map[string]interface{} {
+	return parsePath(iniParser{}, path)
+}
+
+type iniParser struct{}
+
+func (iniParser) Name() string { return "ini" }
+
+func (iniParser) Untyped() bool { return true }
+
+func (iniParser) Patterns() []string { return []string{".ini", ".cfg", ".conf", ".cnf", ".reg"} }
+
+func (iniParser) Sniff(data []byte) float64 {
+	if looksLikeINI(string(data)) {
+		return 0.4
+	}
+	return 0
+}
+
+func (p iniParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	settings, _, err := p.ParsePositions(r)
+	return settings, err
+}
+
+func (iniParser) ParsePositions(r io.Reader) (map[string]interface{}, map[string]Position, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	kv, at := parseINI(string(data))
+	return flatten(kv, ""), listPositions(at), nil
+}
+
+func (iniParser) ParseComments(r io.Reader) (map[string]Comment, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	_, at := parseINI(string(data))
+	return lineComments(string(data), ";#", listPositions(at), func(line string) string {
+		if _, val, ok := splitINILine(strings.TrimSpace(line)); ok {
+			_, comment := splitINIComment(val)
+			return comment
+		}
+		return ""
+	}), nil
+}
+
+// parseINI reads INI-style text: [section] headers (and git-style
+// [section "sub"]) prefix the keys below them, ; and # start comments,
+// key = value and key: value are both accepted, keys may be double-quoted,
+// a trailing backslash or a more deeply indented line continues the
+// previous value, and keys repeated within a section collect into a list. The position of every occurrence of
+// each key is returned alongside.
+func parseINI(text string) (map[string]interface{}, map[string][]Position) {
+	kv := make(map[string]interface{})
+	at := make(map[string][]Position)
+	section := ""
+	var lastKey string
+	lastIndent := -1
+	continued := false
+
+	store := func(key, val string) {
+		if prev, ok := kv[key]; ok {
+			if list, ok := prev.([]interface{}); ok {
+				kv[key] = append(list, val)
+			} else {
+				kv[key] = []interface{}{prev, val}
+			}
+			return
+		}
+		kv[key] = val
+	}
+	appendToLast := func(more, sep string) {
+		switch prev := kv[lastKey].(type) {
+		case []interface{}:
+			last := prev[len(prev)-1].(string)
+			prev[len(prev)-1] = joinContinuation(last, more, sep)
+		case string:
+			kv[lastKey] = joinContinuation(prev, more, sep)
+		}
+	}
+
+	for n, raw := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := strings.TrimSpace(raw)
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+
+		if continued {
+			continued = strings.HasSuffix(line, `\`)
+			appendToLast(strings.TrimSuffix(line, `\`), " ")
+			continue
+		}
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if m := iniSection.FindStringSubmatch(line); m != nil {
+			section = m[1]
+			if m[2] != "" {
+				section += "." + m[2]
+			}
+			lastKey = ""
+			continue
+		}
+		if lastKey != "" && indent > lastIndent {
+			appendToLast(line, "\n")
+			continue
+		}
+
+		key, val, ok := splitINILine(line)
+		if !ok {
+			if !iniBareWord.MatchString(line) {
+				lastKey = ""
+				continue
+			}
+			key, val = line, ""
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		if strings.HasSuffix(val, `\`) {
+			continued = true
+			val = strings.TrimSpace(strings.TrimSuffix(val, `\`))
+		} else {
+			val = unquoteINIValue(val)
+		}
+		store(key, val)
+		at[key] = append(at[key], Position{Line: n + 1, Column: indent + 1})
+		lastKey, lastIndent = key, indent
+	}
+	return kv, at
+}
+
+// splitINILine splits on whichever of = or : comes first, outside a quoted
+// key such as the "Name"="value" lines of Windows .reg exports.
+func splitINILine(line string) (string, string, bool) {
+	if len(line) > 1 && line[0] == '"' {
+		if end := strings.IndexByte(line[1:], '"'); end > 0 {
+			rest := strings.TrimSpace(line[end+2:])
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				return line[1 : end+1], strings.TrimSpace(rest[1:]), true
+			}
+		}
+	}
+	i := strings.IndexAny(line, "=:")
+	if i <= 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+}
+
+// unquoteINIValue strips matching surrounding quotes, or an inline comment
+// from an unquoted value.
+func unquoteINIValue(val string) string {
+	if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+		return val[1 : len(val)-1]
+	}
+	val, _ = splitINIComment(val)
+	return val
+}
+
+// splitINIComment separates an inline ; or # comment, which must follow
+// whitespace, from an unquoted value.
+func splitINIComment(val string) (string, string) {
+	if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+		return val, ""
+	}
+	cut := -1
+	for _, marker := range []string{" ;", "\t;", " #", "\t#"} {
+		if i := strings.Index(val, marker); i > 0 && (cut < 0 || i < cut) {
+			cut = i
+		}
+	}
+	if cut < 0 {
+		return val, ""
+	}
+	return strings.TrimSpace(val[:cut]), strings.TrimSpace(val[cut:])
+}
+
+func joinContinuation(prev, more, sep string) string {
+	more = strings.TrimSpace(more)
+	if prev == "" {
+		return more
+	}
+	if more == "" {
+		return prev
+	}
+	return prev + sep + more
+}
+
+// looksLikeINI reports whether text contains at least one [section] header.
+func looksLikeINI(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if iniSection.MatchString(strings.TrimSpace(line)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseINI_Sections(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "my.cnf.ini")
+	os.WriteFile(file, []byte(`; global comment
+user = root
+
+[mysqld]
+port = 3306   ; inline comment
+socket: "/var/run/mysqld.sock"
+skip-networking
+# hash comment
+init_command = SET NAMES utf8 \
+    COLLATE utf8_bin
+
+[client]
+port = 3307
+"ssl mode" = "required"
+
+[remote "origin"]
+	url = git@example.com:repo.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+	fetch = +refs/tags/*:refs/tags/*
+
+[options]
+install_requires =
+    requests
+    click
+`), 0644)
+
+	result, format := ParseFile(file)
+	if format != "ini" {
+		t.Errorf("Expected format ini, got %s", format)
+	}
+	expected := map[string]interface{}{
+		"user":                     "root",
+		"mysqld.port":              "3306",
+		"mysqld.socket":            "/var/run/mysqld.sock",
+		"mysqld.skip-networking":   "",
+		"mysqld.init_command":      "SET NAMES utf8 COLLATE utf8_bin",
+		"client.port":              "3307",
+		"client.ssl mode":          "required",
+		"remote.origin.url":        "git@example.com:repo.git",
+		"options.install_requires": "requests\nclick",
+	}
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("Expected %s = %q, got %q", k, v, result[k])
+		}
+	}
+	if result["remote.origin.fetch[1]"] != "+refs/tags/*:refs/tags/*" {
+		t.Errorf("Expected repeated fetch keys to be indexed, got %v", result)
+	}
+}
+
+func TestParseINI_MySQLConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "my.cnf")
+	os.WriteFile(file, []byte("[mysqld]\nport=3306\n"), 0644)
+
+	result, err := Parse(file, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Format != "ini" || result.DetectedBy != DetectedByName {
+		t.Errorf("Expected my.cnf to be ini by name, got %s by %s", result.Format, result.DetectedBy)
+	}
+	if result.Settings["mysqld.port"] != "3306" {
+		t.Errorf("Expected mysqld.port = 3306, got %v", result.Settings)
+	}
+	if !slices.Contains(Patterns(), ".cnf") {
+		t.Errorf("Expected .cnf among the scanned patterns, got %v", Patterns())
+	}
+}
@@ -2,20 +2,54 @@ package parser
 
 import (
 	"encoding/json"
-	"os"
+	"io"
+	"strings"
 )
 
 func ParseJSON(path string) map[string]interface{} {
-	data, err := os.ReadFile(path)
+	return parsePath(jsonParser{}, path)
+}
+
+type jsonParser struct{}
+
+func (jsonParser) Name() string { return "json" }
+
+func (jsonParser) Patterns() []string { return []string{".json"} }
+
+// Sniff claims any valid JSON value, scalars included, so stdin is read the
+// same way as a .json file holding the same bytes.
+func (jsonParser) Sniff(data []byte) float64 {
+	if strings.TrimSpace(string(data)) != "" && json.Valid(data) {
+		return 1
+	}
+	return 0
+}
+
+func (p jsonParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	settings, _, err := p.ParsePositions(r)
+	return settings, err
+}
+
+func (jsonParser) ParsePositions(r io.Reader) (map[string]interface{}, map[string]Position, error) {
+	data, err := io.ReadAll(r)
 	if err != nil {
-		return map[string]interface{}{"error": err.Error()}
+		return nil, nil, err
 	}
 
-	var result map[string]interface{}
+	var result interface{}
 	err = json.Unmarshal(data, &result)
 	if err != nil {
-		return map[string]interface{}{"error": err.Error()}
+		// comments and trailing commas are common in hand-edited .json files
+		if relaxed, rerr := parseJSONC(data); rerr == nil {
+			return relaxed, keepPositions(jsonPositions(relaxedToStrictJSON(data)), relaxed), nil
+		}
+		return nil, nil, err
 	}
 
-	return flatten(result, "")
+	settings := flattenRoot(result)
+	if result == nil {
+		// a null document is a scalar like any other
+		settings = map[string]interface{}{RootKey: nil}
+	}
+	return settings, keepPositions(jsonPositions(data), settings), nil
 }
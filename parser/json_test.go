@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseJSON_NonObjectRoots(t *testing.T) {
+	dir := t.TempDir()
+	arrayFile := filepath.Join(dir, "flags.json")
+	scalarFile := filepath.Join(dir, "version.json")
+	os.WriteFile(arrayFile, []byte(`[{"name": "beta", "enabled": true}, "legacy"]`), 0644)
+	os.WriteFile(scalarFile, []byte(`"1.2.3"`), 0644)
+
+	result, _ := ParseFile(arrayFile)
+	if result["[0].name"] != "beta" || result["[0].enabled"] != true || result["[1]"] != "legacy" {
+		t.Errorf("Expected indexed keys for root array, got %v", result)
+	}
+	stdin, format := ParseBytes([]byte(`[{"name": "beta", "enabled": true}, "legacy"]`))
+	if format != "json" || len(stdin) != len(result) || stdin["[0].name"] != "beta" {
+		t.Errorf("Expected stdin to match file parsing, got %v [%s]", stdin, format)
+	}
+
+	result, _ = ParseFile(scalarFile)
+	if result[RootKey] != "1.2.3" {
+		t.Errorf("Expected scalar root under %q, got %v", RootKey, result)
+	}
+
+	// files and stdin agree on every kind of root
+	for _, doc := range []string{`42`, `true`, `null`, `"1.2.3"`, `{}`, `[]`, ` 3.5 `} {
+		file := filepath.Join(dir, "root.json")
+		os.WriteFile(file, []byte(doc), 0644)
+		fromFile, format := ParseFile(file)
+		stdin, stdinFormat := ParseBytes([]byte(doc))
+		if format != "json" || stdinFormat != "json" || !reflect.DeepEqual(fromFile, stdin) {
+			t.Errorf("%s: expected the same json settings from file and stdin, got %v [%s] and %v [%s]", doc, fromFile, format, stdin, stdinFormat)
+		}
+	}
+	for doc, expected := range map[string]interface{}{`42`: float64(42), `true`: true, `null`: nil} {
+		stdin, _ := ParseBytes([]byte(doc))
+		if v, ok := stdin[RootKey]; !ok || v != expected || len(stdin) != 1 {
+			t.Errorf("%s: expected {%s: %v}, got %v", doc, RootKey, expected, stdin)
+		}
+	}
+}
+
+func TestParseYAML_ListRoot(t *testing.T) {
+	result, format := ParseBytes([]byte("- host: a\n- host: b\n"))
+	if format != "yaml" || result["[1].host"] != "b" {
+		t.Errorf("Expected indexed keys for root list, got %v [%s]", result, format)
+	}
+}
@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+func ParseJSONC(path string) map[string]interface{} {
+	return parsePath(relaxedJSONParser{name: "jsonc"}, path)
+}
+
+// relaxedJSONParser handles JSONC and JSON5 files.
+type relaxedJSONParser struct {
+	name     string
+	patterns []string
+}
+
+func (p relaxedJSONParser) Name() string { return p.name }
+
+func (p relaxedJSONParser) Patterns() []string { return p.patterns }
+
+// Sniff ranks jsonc above json5 so content-only detection reports "jsonc".
+func (p relaxedJSONParser) Sniff(data []byte) float64 {
+	if !looksLikeJSONC(string(data)) || !json.Valid(relaxedToStrictJSON(data)) {
+		return 0
+	}
+	if p.name == "jsonc" {
+		return 0.8
+	}
+	return 0.75
+}
+
+func (p relaxedJSONParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	settings, _, err := p.ParsePositions(r)
+	return settings, err
+}
+
+// ParsePositions reports lines exactly; columns can drift by the quotes
+// added around unquoted keys.
+func (relaxedJSONParser) ParsePositions(r io.Reader) (map[string]interface{}, map[string]Position, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	strict := relaxedToStrictJSON(data)
+	settings, err := parseStrictJSONC(strict)
+	if err != nil {
+		return nil, nil, err
+	}
+	return settings, keepPositions(jsonPositions(strict), settings), nil
+}
+
+// parseJSONC parses the relaxed JSON found in VS Code settings, tsconfig.json
+// and JSON5 files by rewriting it to strict JSON first.
+func parseJSONC(data []byte) (map[string]interface{}, error) {
+	return parseStrictJSONC(relaxedToStrictJSON(data))
+}
+
+// parseStrictJSONC is parseJSONC on data already rewritten to strict JSON.
+func parseStrictJSONC(strict []byte) (map[string]interface{}, error) {
+	var result interface{}
+	if err := json.Unmarshal(strict, &result); err != nil {
+		return nil, err
+	}
+	return flattenRoot(result), nil
+}
+
+// looksLikeJSONC reports whether text opens with a comment followed by JSON.
+func looksLikeJSONC(text string) bool {
+	text = strings.TrimSpace(string(relaxedToStrictJSON([]byte(text))))
+	return strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[")
+}
+
+// relaxedToStrictJSON rewrites relaxed JSON into strict JSON: // and /* */
+// comments are blanked out (newlines are kept so line numbers still match),
+// trailing commas before } or ] are dropped, unquoted object keys are quoted,
+// and single-quoted strings become double-quoted.
+func relaxedToStrictJSON(data []byte) []byte {
+	src := string(data)
+	var out strings.Builder
+	out.Grow(len(src))
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '"' || c == '\'':
+			end, closed := jsonStringEnd(src, i)
+			if c == '"' || !closed {
+				// an unterminated string is left for the JSON decoder to reject
+				out.WriteString(src[i:end])
+			} else {
+				out.WriteString(requoteSingle(src[i+1 : end-1]))
+			}
+			i = end - 1
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			out.WriteString(strings.Repeat(" ", end))
+			i += end - 1
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i - 2
+			} else {
+				end += 2
+			}
+			for _, r := range src[i : i+2+end] {
+				if r == '\n' {
+					out.WriteByte('\n')
+				} else {
+					out.WriteByte(' ')
+				}
+			}
+			i += 2 + end - 1
+		case c == ',':
+			if next := nextSignificant(src, i+1); next == '}' || next == ']' {
+				out.WriteByte(' ')
+			} else {
+				out.WriteByte(c)
+			}
+		case isIdentStart(c):
+			j := i
+			for j < len(src) && isIdentPart(src[j]) {
+				j++
+			}
+			if nextSignificant(src, j) == ':' {
+				out.WriteString(`"` + src[i:j] + `"`)
+			} else {
+				out.WriteString(src[i:j])
+			}
+			i = j - 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return []byte(out.String())
+}
+
+// jsonStringEnd returns the index just past the string literal opening at i,
+// and false with len(src) when the literal is never closed.
+func jsonStringEnd(src string, i int) (int, bool) {
+	quote := src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1, true
+		}
+	}
+	return len(src), false
+}
+
+// requoteSingle turns the body of a single-quoted string into a
+// double-quoted one.
+func requoteSingle(body string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(body); i++ {
+		switch {
+		case body[i] == '\\' && i+1 < len(body) && body[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+		case body[i] == '\\' && i+1 < len(body):
+			b.WriteString(body[i : i+2])
+			i++
+		case body[i] == '"':
+			b.WriteString(`\"`)
+		default:
+			b.WriteByte(body[i])
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// nextSignificant returns the next byte from i that is not whitespace or
+// inside a comment, or 0 at end of input.
+func nextSignificant(src string, i int) byte {
+	for i < len(src) {
+		switch {
+		case src[i] == ' ' || src[i] == '\t' || src[i] == '\n' || src[i] == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				return 0
+			}
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return 0
+			}
+			i += end + 4
+		default:
+			return src[i]
+		}
+	}
+	return 0
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseJSONC_Relaxed(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "settings.json")
+	os.WriteFile(file, []byte(`// VS Code settings
+{
+	/* editor */
+	"editor.fontSize": 14,
+	files: {
+		exclude: ['**/.git', "**/node_modules",],
+	},
+	'quote': 'it\'s "fine"', // trailing comment
+	"url": "http://example.com/*not-a-comment*/",
+}
+`), 0644)
+
+	result, format := ParseFile(file)
+	if format != "json" {
+		t.Errorf("Expected format json, got %s", format)
+	}
+	expected := map[string]interface{}{
+		"editor.fontSize":  float64(14),
+		"files.exclude[0]": "**/.git",
+		"files.exclude[1]": "**/node_modules",
+		"quote":            `it's "fine"`,
+		"url":              "http://example.com/*not-a-comment*/",
+	}
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("Expected %s = %v, got %v", k, v, result[k])
+		}
+	}
+
+	data, _ := os.ReadFile(file)
+	stdin, format := ParseBytes(data)
+	if format != "jsonc" || stdin["files.exclude[1]"] != "**/node_modules" {
+		t.Errorf("Expected stdin to parse as jsonc, got %v [%s]", stdin, format)
+	}
+	// an unterminated single-quoted string must not crash detection
+	for _, input := range []string{"{'", "x '", "don't say 'hi'", "{'a': 'b"} {
+		if _, format := ParseBytes([]byte(input)); format == "jsonc" || format == "json5" {
+			t.Errorf("Expected %q not to parse as relaxed JSON, got %s", input, format)
+		}
+	}
+}
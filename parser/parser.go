@@ -1,32 +1,259 @@
 package parser
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
+// Parser reads one configuration format. Implementations are registered
+// with Register; ParseFile, ParseBytes and Patterns are all derived from the
+// registered set.
+type Parser interface {
+	// Name is the format reported alongside parsed settings, e.g. "json".
+	Name() string
+	// Patterns lists the file names handled by the parser: extensions such
+	// as ".json" are matched as suffixes, globs such as ".env.*" against the
+	// whole base name. Matching is case-insensitive.
+	Patterns() []string
+	// Sniff returns how confident the parser is, from 0 to 1, that data is
+	// in its format. 0 means never try this parser on the content.
+	Sniff(data []byte) float64
+	// Parse reads a document and returns its flattened settings.
+	Parse(r io.Reader) (map[string]interface{}, error)
+}
+
+// ContextParser is implemented by parsers that can stop early, such as
+// plugins running an external command. ParseContext uses it when available.
+type ContextParser interface {
+	Parser
+	// ParseContext is Parse giving up once ctx is done.
+	ParseContext(ctx context.Context, r io.Reader) (map[string]interface{}, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Parser
+)
+
+func init() {
+	// Order matters only for ties: a later registration wins, so text goes
+	// first and anything registered from outside the package overrides the
+	// built-ins.
+	for _, p := range []Parser{
+		textParser{},
+		jsonParser{},
+		relaxedJSONParser{name: "jsonc", patterns: []string{".jsonc"}},
+		relaxedJSONParser{name: "json5", patterns: []string{".json5"}},
+		xmlParser{},
+		hclParser{},
+		tomlParser{},
+		yamlParser{},
+		iniParser{},
+		propertiesParser{},
+		dotenvParser{},
+	} {
+		Register(p)
+	}
+}
+
+// Register adds a parser to the registry. A parser registered later takes
+// precedence over earlier ones for the same file pattern and sniff
+// confidence, which lets embedding programs replace built-in formats.
+func Register(p Parser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, p)
+}
+
+// Parsers returns the registered parsers in registration order.
+func Parsers() []Parser {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Parser(nil), registry...)
+}
+
+// Lookup returns the parser registered under the given format name, or nil.
+func Lookup(name string) Parser {
+	parsers := Parsers()
+	for i := len(parsers) - 1; i >= 0; i-- {
+		if parsers[i].Name() == name {
+			return parsers[i]
+		}
+	}
+	return nil
+}
+
+// Patterns returns every file pattern handled by a registered parser, for
+// use as the scanner's extension list.
+func Patterns() []string {
+	seen := make(map[string]bool)
+	var patterns []string
+	for _, p := range Parsers() {
+		for _, pattern := range p.Patterns() {
+			pattern = strings.ToLower(pattern)
+			if !seen[pattern] {
+				seen[pattern] = true
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	return patterns
+}
+
+// MatchPattern reports whether a file name matches a parser pattern: a
+// suffix such as ".json", or a glob such as ".env.*" against the base name.
+func MatchPattern(name, pattern string) bool {
+	name = strings.ToLower(filepath.Base(name))
+	pattern = strings.ToLower(pattern)
+	if strings.ContainsAny(pattern, "*?[") {
+		ok, _ := filepath.Match(pattern, name)
+		return ok
+	}
+	return strings.HasSuffix(name, pattern)
+}
+
+// ParseFile parses the file at path with the parser registered for its
+// name, sniffing the content when the name is unknown or ambiguous and
+// falling back to plain key=value text. It returns the flattened settings
+// and the format name ("json", "yaml", "xml", ...). A file that cannot be
+// read or parsed has no settings; use Parse to find out why.
+//
+//	data, format := parser.ParseFile("/path/to/config.json")
 func ParseFile(path string) (map[string]interface{}, string) {
-	ext := strings.ToLower(filepath.Ext(path))
-
-	switch ext {
-	case ".json":
-		return ParseJSON(path), "json"
-	case ".yaml", ".yml":
-		return ParseYAML(path), "yaml"
-	case ".xml":
-		return ParseXML(path), "xml"
-	default:
-		return ParseText(path), "text"
-	}
-}
-
-// ParseFile takes a file path and determines its format based on the file extension.
-// It returns a map of parsed data and the format type as a string.
-//// Supported formats include JSON, YAML, XML, and plain text.
-// If the file format is not recognized, it defaults to plain text.
-//// Parameters:	- path: A string representing the full path of the file to be parsed.
-//// Returns:	- A map containing the parsed data from the file.	- A string indicating the format of the file (e.g., "json", "yaml", "xml", "text").
-//// Example usage:
-//   data, format := parser.ParseFile("/path/to/config.json")
-//   fmt.Printf("Format: %s, Data: %v\n", format, data)
-//				}
+	result, err := Parse(path, Options{})
+	if err != nil {
+		return map[string]interface{}{}, result.Format
+	}
+	return result.Settings, result.Format
+}
+
+// Result is the outcome of parsing one file.
+type Result struct {
+	Settings map[string]interface{}
+	// Format is the name of the parser that produced Settings.
+	Format string
+	// DetectedBy says how Format was chosen: DetectedByName,
+	// DetectedByContent or DetectedByFallback.
+	DetectedBy string
+	// Positions holds where each setting is defined, for formats whose
+	// parser is a PositionParser.
+	Positions map[string]Position
+	// Keys lists the keys of Settings in document order where positions,
+	// or for an OrderParser the order, are known, then by name.
+	Keys []string
+	// Comments holds the comments documenting settings, for formats whose
+	// parser is a CommentParser.
+	Comments map[string]Comment
+	// Normalized holds every setting as a Value when Options.InferTypes is
+	// set.
+	Normalized map[string]Value
+	// Encoding is the character encoding the file was written in, one of
+	// the Encoding constants. Parsers always see UTF-8.
+	Encoding string
+	// Embedded maps keys whose string value held a config document, expanded
+	// when Options.EmbeddedDepth is set, to how it was read ("yaml",
+	// "base64+json"). The document's settings replace the key.
+	Embedded map[string]string
+}
+
+// Options change how Parse reports settings. The zero value gives bracket
+// indexes, raw values only and no embedded documents.
+type Options struct {
+	// IndexNotation is how list indexes appear in keys.
+	IndexNotation ArrayNotation
+	// InferTypes fills Result.Normalized. Values of untyped formats such as
+	// INI or dotenv are inferred from their text; see Normalize.
+	InferTypes bool
+	// EmbeddedDepth is how many levels of configs stored inside string
+	// values are expanded, such as the YAML files in a Kubernetes ConfigMap
+	// or the base64 data of a Secret. 0 leaves values alone.
+	EmbeddedDepth int
+}
+
+// finish applies opts to a result whose settings are complete.
+func (opts Options) finish(result *Result, untyped bool) *Result {
+	if opts.InferTypes {
+		result.Normalized = normalizeSettings(result.Settings, untyped, result.Embedded)
+	}
+	if opts.IndexNotation == DotNotation {
+		useDotIndexes(result)
+	}
+	return result
+}
+
+// newResult completes the result of p parsing data successfully.
+func newResult(p Parser, by, encoding string, data []byte, settings map[string]interface{}, positions map[string]Position, comments map[string]Comment, opts Options) *Result {
+	result := &Result{
+		Settings:   settings,
+		Format:     p.Name(),
+		DetectedBy: by,
+		Encoding:   encoding,
+		Positions:  positions,
+		Keys:       documentOrder(p, data, settings, positions),
+		Comments:   documentComments(p, data, settings, comments),
+	}
+	expandEmbedded(result, opts.EmbeddedDepth)
+	return opts.finish(result, isUntyped(p))
+}
+
+// Parse is ParseFile with the detection method reported in the result and
+// failures returned as an error: a *ParseError when the content is invalid,
+// or the underlying error when the file cannot be read. The result is never
+// nil.
+func Parse(path string, opts Options) (*Result, error) {
+	return ParseContext(context.Background(), path, opts)
+}
+
+// ParseContext is Parse giving up once ctx is done, in which case the error
+// is ctx.Err(). Plugins still running are stopped.
+func ParseContext(ctx context.Context, path string, opts Options) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		p, by := parserForFile(path, nil)
+		return &Result{Format: p.Name(), DetectedBy: by}, err
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		p, by := parserForFile(path, nil)
+		return &Result{Format: p.Name(), DetectedBy: by}, err
+	}
+
+	data, encoding := decodeText(raw)
+	p, by := parserForFile(path, data)
+	if by == DetectedByFallback {
+		// unknown or generic name: let the content decide
+		if p, m, positions, comments := detectContent(ctx, data); p != nil {
+			return newResult(p, DetectedByContent, encoding, data, m, positions, comments, opts), nil
+		}
+	}
+	data = parserInput(p, raw, data)
+	settings, positions, comments, err := parseDocument(ctx, p, data)
+	if err != nil {
+		result := &Result{Format: p.Name(), DetectedBy: by, Encoding: encoding}
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		return result, newParseError(p.Name(), path, data, err)
+	}
+	return newResult(p, by, encoding, data, settings, positions, comments, opts), nil
+}
+
+// parsePath runs p over the file at path, transcoded to UTF-8 unless p is a
+// RawParser. Failures yield no settings.
+func parsePath(p Parser, path string) map[string]interface{} {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+
+	data, _ := decodeText(raw)
+	result, err := p.Parse(bytes.NewReader(parserInput(p, raw, data)))
+	if err != nil {
+		return map[string]interface{}{}
+	}
+	return result
+}
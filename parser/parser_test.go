@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// acmeParser is an in-house format: "key -> value" lines behind an ACME header.
+type acmeParser struct{}
+
+func (acmeParser) Name() string { return "acme" }
+
+func (acmeParser) Patterns() []string { return []string{".acme", "acme-*.cfg"} }
+
+func (acmeParser) Sniff(data []byte) float64 {
+	if strings.HasPrefix(string(data), "#ACME") {
+		return 1
+	}
+	return 0
+}
+
+func (acmeParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	kv := make(map[string]interface{})
+	for _, line := range strings.Split(string(data), "\n") {
+		if parts := strings.SplitN(line, "->", 2); len(parts) == 2 {
+			kv[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return kv, nil
+}
+
+// saveRegistry returns a func restoring the registry to its current
+// parsers, so tests registering their own don't leak them into later tests.
+func saveRegistry() func() {
+	registryMu.RLock()
+	saved := append([]Parser(nil), registry...)
+	registryMu.RUnlock()
+	return func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		registry = saved
+	}
+}
+
+func TestRegister_CustomParser(t *testing.T) {
+	t.Cleanup(saveRegistry())
+	Register(acmeParser{})
+
+	found := false
+	for _, p := range Patterns() {
+		if p == ".acme" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected .acme in registered patterns, got %v", Patterns())
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "router.acme")
+	os.WriteFile(file, []byte("#ACME\nmode -> turbo\n"), 0644)
+	result, format := ParseFile(file)
+	if format != "acme" || result["mode"] != "turbo" {
+		t.Errorf("Expected acme parser for .acme file, got %v [%s]", result, format)
+	}
+
+	// acme-*.cfg is also claimed by the INI parser; sniffing settles it
+	iniFile := filepath.Join(dir, "acme-site.cfg")
+	os.WriteFile(iniFile, []byte("[site]\nmode = slow\n"), 0644)
+	if _, format := ParseFile(iniFile); format != "ini" {
+		t.Errorf("Expected ini parser for INI content, got %s", format)
+	}
+
+	result, format = ParseBytes([]byte("#ACME\nmode -> turbo\n"))
+	if format != "acme" || result["mode"] != "turbo" {
+		t.Errorf("Expected acme parser for sniffed content, got %v [%s]", result, format)
+	}
+}
+
+func TestSaveRegistry(t *testing.T) {
+	restore := saveRegistry()
+	Register(acmeParser{})
+	restore()
+	if Lookup("acme") != nil {
+		t.Errorf("Expected the registry to be restored without acme")
+	}
+}
+
+func TestParseContext_Cancelled(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.json")
+	os.WriteFile(file, []byte(`{"port": 8080}`), 0644)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := ParseContext(ctx, file, Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if result == nil || result.Format != "json" {
+		t.Errorf("Expected a result naming the format, got %+v", result)
+	}
+}
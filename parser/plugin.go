@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultPluginTimeout bounds a plugin run when no timeout is configured.
+const DefaultPluginTimeout = 10 * time.Second
+
+// Plugin is a Parser backed by an external executable. The file content is
+// piped to the command's stdin, and the command writes a JSON object of
+// settings to stdout; nested objects and arrays are flattened like JSON
+// files. A non-zero exit status, a timeout or invalid output is reported as
+// a *PluginError.
+type Plugin struct {
+	name     string
+	command  string
+	args     []string
+	patterns []string
+	timeout  time.Duration
+}
+
+// NewPlugin returns a plugin parser reporting format name for files matching
+// patterns. A zero timeout means DefaultPluginTimeout.
+func NewPlugin(name, command string, args, patterns []string, timeout time.Duration) *Plugin {
+	if timeout <= 0 {
+		timeout = DefaultPluginTimeout
+	}
+	return &Plugin{name: name, command: command, args: args, patterns: patterns, timeout: timeout}
+}
+
+// PluginError reports a failed plugin run.
+type PluginError struct {
+	Plugin string
+	Err    error
+}
+
+func (e *PluginError) Error() string {
+	return fmt.Sprintf("plugin %s: %v", e.Plugin, e.Err)
+}
+
+func (e *PluginError) Unwrap() error { return e.Err }
+
+func (p *Plugin) Name() string { return p.name }
+
+func (p *Plugin) Patterns() []string { return p.patterns }
+
+// Sniff never claims content: plugins are chosen by file pattern only.
+func (p *Plugin) Sniff(data []byte) float64 { return 0 }
+
+// Raw is true: the command gets the file exactly as stored, whatever its
+// encoding.
+func (p *Plugin) Raw() bool { return true }
+
+func (p *Plugin) Parse(r io.Reader) (map[string]interface{}, error) {
+	return p.ParseContext(context.Background(), r)
+}
+
+// ParseContext is Parse killing the command once ctx is done.
+func (p *Plugin) ParseContext(parent context.Context, r io.Reader) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(parent, p.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.Stdin = r
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// don't wait forever on grandchildren still holding stdout after a kill
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if parent.Err() != nil {
+			return nil, &PluginError{Plugin: p.name, Err: parent.Err()}
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, &PluginError{Plugin: p.name, Err: fmt.Errorf("timed out after %s", p.timeout)}
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%v: %s", err, msg)
+		}
+		return nil, &PluginError{Plugin: p.name, Err: err}
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, &PluginError{Plugin: p.name, Err: fmt.Errorf("invalid output: %v", err)}
+	}
+	return flattenRoot(result), nil
+}
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writePluginScript(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin tests use a shell script")
+	}
+	script := filepath.Join(t.TempDir(), "plugin.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\n"+body), 0755)
+	return script
+}
+
+func TestPlugin_Parse(t *testing.T) {
+	script := writePluginScript(t, `tr 'a-z' 'A-Z' >/dev/null; echo '{"device": {"name": "fw1", "ports": [22, 443]}}'`)
+	p := NewPlugin("appliance", script, nil, []string{".apx"}, time.Second)
+
+	result, err := p.Parse(strings.NewReader("binary blob"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result["device.name"] != "fw1" || result["device.ports[1]"] != float64(443) {
+		t.Errorf("Expected flattened plugin output, got %v", result)
+	}
+}
+
+func TestPlugin_Errors(t *testing.T) {
+	cases := map[string]string{
+		"exit":    `echo "bad magic" >&2; exit 3`,
+		"timeout": `exec sleep 5`,
+		"output":  `echo not json`,
+	}
+	for name, body := range cases {
+		p := NewPlugin("appliance", writePluginScript(t, body), nil, nil, 200*time.Millisecond)
+		_, err := p.Parse(strings.NewReader(""))
+		var pluginErr *PluginError
+		if !errors.As(err, &pluginErr) {
+			t.Errorf("%s: expected *PluginError, got %v", name, err)
+		}
+	}
+}
+
+func TestPlugin_ParseContextCancelled(t *testing.T) {
+	p := NewPlugin("appliance", writePluginScript(t, `exec sleep 5`), nil, nil, 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := p.ParseContext(ctx, strings.NewReader(""))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the caller's deadline as the cause, got %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("Expected the plugin to be stopped with its context, took %s", time.Since(start))
+	}
+}
+
+func TestPlugin_GetsRawBytes(t *testing.T) {
+	t.Cleanup(saveRegistry())
+	script := writePluginScript(t, `printf '{"hex": "%s"}' "$(od -An -tx1 | tr -d ' \n')"`)
+	Register(NewPlugin("legacy", script, nil, []string{".lgc"}, time.Second))
+
+	file := filepath.Join(t.TempDir(), "device.lgc")
+	os.WriteFile(file, []byte{0x81, 0x82, 0xff, 0x10}, 0644)
+	result, err := Parse(file, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Settings["hex"] != "8182ff10" {
+		t.Errorf("Expected the plugin to get the file untranscoded, got %v", result.Settings)
+	}
+}
+
+func TestPlugin_WinsOverBuiltinPatterns(t *testing.T) {
+	t.Cleanup(saveRegistry())
+	script := writePluginScript(t, `echo '{"from": "plugin"}'`)
+	Register(NewPlugin("vendor-ini", script, nil, []string{".ini", "*.txt"}, time.Second))
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"app.ini":   "[server]\nport = 8080\n",
+		"notes.txt": "port = 8080\n",
+	} {
+		file := filepath.Join(dir, name)
+		os.WriteFile(file, []byte(content), 0644)
+		result, err := Parse(file, Options{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if result.Format != "vendor-ini" || result.DetectedBy != DetectedByName || result.Settings["from"] != "plugin" {
+			t.Errorf("%s: expected the declared plugin to win, got %v [%s, by %s]", name, result.Settings, result.Format, result.DetectedBy)
+		}
+	}
+}
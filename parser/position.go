@@ -0,0 +1,222 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// Position is where a setting is defined in its file. Line and Column are
+// 1-based.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// lineIndex holds where each line of a document starts, so positions can be
+// looked up by offset without rescanning the text before it.
+type lineIndex []int
+
+func newLineIndex(data []byte) lineIndex {
+	starts := lineIndex{0}
+	for i, c := range data {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// position converts a byte offset into a 1-based line and column, as
+// offsetPosition does.
+func (li lineIndex) position(offset int64) Position {
+	line := sort.Search(len(li), func(i int) bool { return int64(li[i]) > offset })
+	return Position{Line: line, Column: int(offset) - li[line-1] + 1}
+}
+
+// PositionParser is implemented by parsers that can tell where each
+// flattened setting was defined. Parse uses it when available.
+type PositionParser interface {
+	Parser
+	// ParsePositions is Parse plus the position of each returned key.
+	// Keys without a known position may be left out.
+	ParsePositions(r io.Reader) (map[string]interface{}, map[string]Position, error)
+}
+
+// parseDocument runs p over data, collecting positions if p can report them
+// and passing ctx on if p can be cancelled. Comments come back only from a
+// parser that reads them in the same pass; see documentComments.
+func parseDocument(ctx context.Context, p Parser, data []byte) (map[string]interface{}, map[string]Position, map[string]Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	if dp, ok := p.(documentParser); ok {
+		return dp.readDocument(data)
+	}
+	if pp, ok := p.(PositionParser); ok {
+		settings, positions, err := pp.ParsePositions(bytes.NewReader(data))
+		return settings, positions, nil, err
+	}
+	if cp, ok := p.(ContextParser); ok {
+		settings, err := cp.ParseContext(ctx, bytes.NewReader(data))
+		return settings, nil, nil, err
+	}
+	settings, err := p.Parse(bytes.NewReader(data))
+	return settings, nil, nil, err
+}
+
+// keepPositions drops positions for keys that are not settings.
+func keepPositions(positions map[string]Position, settings map[string]interface{}) map[string]Position {
+	kept := make(map[string]Position, len(settings))
+	for k := range settings {
+		if p, ok := positions[k]; ok {
+			kept[k] = p
+		}
+	}
+	return kept
+}
+
+// OrderParser is implemented by parsers that cannot tell where each setting
+// is defined but know the order the settings come in. Parse uses it to order
+// Result.Keys.
+type OrderParser interface {
+	Parser
+	// ParseOrder returns the flattened keys Parse would return, in document
+	// order. Keys left out follow by name.
+	ParseOrder(r io.Reader) ([]string, error)
+}
+
+// documentOrder lists the keys of settings in document order, from their
+// positions or, without any, from p if it is an OrderParser.
+func documentOrder(p Parser, data []byte, settings map[string]interface{}, positions map[string]Position) []string {
+	op, ok := p.(OrderParser)
+	if !ok || len(positions) > 0 {
+		return sourceOrder(settings, positions)
+	}
+	order, err := op.ParseOrder(bytes.NewReader(data))
+	if err != nil {
+		return sourceOrder(settings, nil)
+	}
+	keys := make([]string, 0, len(settings))
+	listed := make(map[string]bool, len(settings))
+	for _, k := range order {
+		if _, ok := settings[k]; ok && !listed[k] {
+			listed[k] = true
+			keys = append(keys, k)
+		}
+	}
+	rest := make(map[string]interface{})
+	for k, v := range settings {
+		if !listed[k] {
+			rest[k] = v
+		}
+	}
+	return append(keys, sourceOrder(rest, nil)...)
+}
+
+// sourceOrder lists the keys of settings in the order they appear in the
+// document. Keys without a known position, which is every key for parsers
+// that are not PositionParsers, follow sorted by name so the order is always
+// reproducible.
+func sourceOrder(settings map[string]interface{}, positions map[string]Position) []string {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		pi, iok := positions[keys[i]]
+		pj, jok := positions[keys[j]]
+		switch {
+		case iok != jok:
+			return iok
+		case iok && pi.Line != pj.Line:
+			return pi.Line < pj.Line
+		case iok && pi.Column != pj.Column:
+			return pi.Column < pj.Column
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// listPositions resolves the positions of flat keys that may repeat: a key
+// seen once keeps its name, a repeated key is indexed like the list it
+// flattens into.
+func listPositions(at map[string][]Position) map[string]Position {
+	positions := make(map[string]Position, len(at))
+	for key, list := range at {
+		if len(list) == 1 {
+			positions[key] = list[0]
+			continue
+		}
+		for i, p := range list {
+			positions[indexKey(key, i)] = p
+		}
+	}
+	return positions
+}
+
+// joinKey appends a map key to a flattened prefix.
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// jsonPositions walks the tokens of a valid JSON document and records where
+// every flattened key (containers included) starts, using the same key
+// names as flattenRoot.
+func jsonPositions(data []byte) map[string]Position {
+	positions := make(map[string]Position)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	lines := newLineIndex(data)
+
+	// next skips separators to where the next key or value begins
+	next := func() Position {
+		off := dec.InputOffset()
+		for off < int64(len(data)) && bytes.IndexByte([]byte(" \t\r\n,:"), data[off]) >= 0 {
+			off++
+		}
+		return lines.position(off)
+	}
+
+	var walk func(key string, at Position) error
+	walk = func(key string, at Position) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		positions[key] = at
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				keyAt := next()
+				k, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				name, _ := k.(string)
+				if err := walk(joinKey(key, name), keyAt); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(indexKey(key, i), next()); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		}
+		return err
+	}
+
+	if walk("", next()) == nil {
+		positions[RootKey] = positions[""]
+	}
+	return positions
+}
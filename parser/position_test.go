@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse_Positions(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name, content string
+		expected      map[string]Position
+	}{
+		{"app.json", "{\n  \"server\": {\n    \"port\": 80\n  },\n  \"hosts\": [\"a\", \"b\"]\n}", map[string]Position{
+			"server.port": {3, 5}, "hosts[1]": {5, 18},
+		}},
+		{"app.yaml", "base: &base\n  level: info\nlog:\n  <<: *base\n  file: x.log\nlist:\n  - one\n", map[string]Position{
+			"base.level": {2, 3}, "log.level": {2, 3}, "log.file": {5, 3}, "list[0]": {7, 5},
+		}},
+		{"app.ini", "[mysqld]\nport = 3306\n  [client]\n  fetch = a\n  fetch = b\n", map[string]Position{
+			"mysqld.port": {2, 1}, "client.fetch[1]": {5, 3},
+		}},
+		{"app.properties", "# c\n  a=1\nb = x \\\n    y\n", map[string]Position{
+			"a": {2, 3}, "b": {3, 1},
+		}},
+		{".env", "A=1\nB=\"multi\nline\"\n  export C=3\n", map[string]Position{
+			"A": {1, 1}, "B": {2, 1}, "C": {4, 10},
+		}},
+		{"app.xml", "<?xml version=\"1.0\"?>\n<app>\n  <server port=\"80\"/>\n  <name>x</name>\n</app>\n", map[string]Position{
+			"app.server.@port": {3, 3}, "app.name": {4, 3},
+		}},
+		{"app.txt", "a=1\n  b = 2\n", map[string]Position{
+			"a": {1, 1}, "b": {2, 3},
+		}},
+		{"main.tf", "region = \"eu\"\njob \"web\" {\n  tags = [\"a\", \"b\"]\n  task {}\n  task {\n    cpu = 2\n  }\n}\n", map[string]Position{
+			"region": {1, 1}, "job.web.tags[1]": {3, 3}, "job.web.task[0]": {4, 3}, "job.web.task[1].cpu": {6, 5},
+		}},
+	}
+	for _, tc := range cases {
+		file := filepath.Join(dir, tc.name)
+		os.WriteFile(file, []byte(tc.content), 0644)
+		result, err := Parse(file, Options{})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		for k, want := range tc.expected {
+			if got, ok := result.Positions[k]; !ok || got != want {
+				t.Errorf("%s: expected %s at %v, got %v (ok=%v)", tc.name, k, want, got, ok)
+			}
+		}
+		for k := range result.Positions {
+			if _, ok := result.Settings[k]; !ok {
+				t.Errorf("%s: position reported for non-setting %q", tc.name, k)
+			}
+		}
+	}
+}
+
+func TestParse_KeyOrder(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name, content string
+		expected      []string
+	}{
+		{"app.yaml", "zeta: 1\nalpha:\n  mid: 2\n  beta: 3\nlist:\n  - x\n  - y\n", []string{"zeta", "alpha.mid", "alpha.beta", "list[0]", "list[1]"}},
+		{"app.json", `{"z": 1, "a": {"y": 2, "b": 3}}`, []string{"z", "a.y", "a.b"}},
+		{"app.ini", "[z]\nk=1\n[a]\nk=2\n", []string{"z.k", "a.k"}},
+		{"app.xml", "<app><z>1</z><a b=\"2\" a=\"3\"/></app>", []string{"app.z", "app.a.@a", "app.a.@b"}},
+		{"app.toml", "z = 1\na = 2\n[m]\nk = 3\n", []string{"z", "a", "m.k"}},
+		{"list.toml", "y = [1, 2]\nx = [{b = 1}, {b = 2}]\n[[srv]]\nname = \"a\"\n[srv.meta]\nk = 1\n[[srv]]\nname = \"b\"\n[[srv.sub]]\nz = 1\n",
+			[]string{"y[0]", "y[1]", "x[0].b", "x[1].b", "srv[0].name", "srv[0].meta.k", "srv[1].name", "srv[1].sub[0].z"}},
+		{"main.tf", "z = 1\nblock \"b\" {\n  y = 2\n  a = 3\n}\nblock \"b\" {\n  x = 4\n}\na = 5\n", []string{"z", "block.b[0].y", "block.b[0].a", "block.b[1].x", "a"}},
+	}
+	for _, tc := range cases {
+		file := filepath.Join(dir, tc.name)
+		os.WriteFile(file, []byte(tc.content), 0644)
+		result, err := Parse(file, Options{})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(result.Keys, tc.expected) {
+			t.Errorf("%s: expected keys %v, got %v", tc.name, tc.expected, result.Keys)
+		}
+	}
+}
+
+func TestParseData_KeyOrder(t *testing.T) {
+	result := ParseData([]byte("b=1\na=2\n"), Options{})
+	if !reflect.DeepEqual(result.Keys, []string{"b", "a"}) {
+		t.Errorf("Expected keys [b a], got %v", result.Keys)
+	}
+	result = ParseData([]byte("just words"), Options{})
+	if result.Format != "raw" || !reflect.DeepEqual(result.Keys, []string{"raw"}) {
+		t.Errorf("Expected a single raw key, got %s %v", result.Format, result.Keys)
+	}
+}
+
+func TestLineIndex(t *testing.T) {
+	for _, data := range []string{"", "a", "\n", "ab\ncd\n\nefg", "x\r\ny\n"} {
+		lines := newLineIndex([]byte(data))
+		for off := 0; off <= len(data); off++ {
+			line, col := offsetPosition([]byte(data), int64(off))
+			if got := lines.position(int64(off)); got != (Position{line, col}) {
+				t.Errorf("%q at %d: expected %d:%d, got %v", data, off, line, col, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+func ParseProperties(path string) map[string]interface{} {
+	return parsePath(propertiesParser{}, path)
+}
+
+// propertiesParser is only chosen by file name: properties syntax accepts
+// almost any text, so content alone is never enough.
+type propertiesParser struct{}
+
+func (propertiesParser) Name() string { return "properties" }
+
+func (propertiesParser) Untyped() bool { return true }
+
+func (propertiesParser) Patterns() []string { return []string{".properties"} }
+
+func (propertiesParser) Sniff(data []byte) float64 {
+	return 0
+}
+
+func (p propertiesParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	settings, _, err := p.ParsePositions(r)
+	return settings, err
+}
+
+func (propertiesParser) ParsePositions(r io.Reader) (map[string]interface{}, map[string]Position, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings, positions := parseProperties(string(data))
+	return settings, positions, nil
+}
+
+// ParseComments reports the # and ! comment lines above each key. Properties
+// have no trailing comments: a # after a value is part of the value.
+func (propertiesParser) ParseComments(r io.Reader) (map[string]Comment, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	_, positions := parseProperties(string(data))
+	return lineComments(string(data), "#!", positions, nil), nil
+}
+
+// parseProperties follows java.util.Properties.load: # and ! comment lines,
+// key/value separated by the first unescaped =, : or whitespace, lines ending
+// in an odd number of backslashes continue onto the next (whose leading
+// whitespace is dropped), and \t \n \r \f \uXXXX escapes in keys and values.
+// Later duplicates win, as in Java, and so does their position.
+func parseProperties(text string) (map[string]interface{}, map[string]Position) {
+	kv := make(map[string]interface{})
+	positions := make(map[string]Position)
+	for _, line := range propertiesLogicalLines(text) {
+		key, val := splitPropertiesLine(line.text)
+		key = unescapeProperties(key)
+		kv[key] = unescapeProperties(val)
+		positions[key] = line.at
+	}
+	return kv, positions
+}
+
+// propertiesLine is a logical line and where it starts.
+type propertiesLine struct {
+	text string
+	at   Position
+}
+
+// propertiesLogicalLines joins continued natural lines and drops blanks and
+// comments.
+func propertiesLogicalLines(text string) []propertiesLine {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var lines []propertiesLine
+	var current strings.Builder
+	var start Position
+	continuing := false
+	for n, natural := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(natural, " \t\f")
+		if !continuing {
+			if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+				continue
+			}
+			start = Position{Line: n + 1, Column: len(natural) - len(trimmed) + 1}
+		}
+		if trailingBackslashes(trimmed)%2 == 1 {
+			current.WriteString(trimmed[:len(trimmed)-1])
+			continuing = true
+			continue
+		}
+		current.WriteString(trimmed)
+		lines = append(lines, propertiesLine{current.String(), start})
+		current.Reset()
+		continuing = false
+	}
+	if continuing {
+		lines = append(lines, propertiesLine{current.String(), start})
+	}
+	return lines
+}
+
+func trailingBackslashes(s string) int {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n
+}
+
+// splitPropertiesLine returns the still-escaped key and value of a logical
+// line.
+func splitPropertiesLine(line string) (string, string) {
+	keyEnd := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			keyEnd = i
+			break
+		}
+	}
+	rest := strings.TrimLeft(line[keyEnd:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return line[:keyEnd], rest
+}
+
+func unescapeProperties(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					i += 4
+					// surrogate pairs arrive as two consecutive escapes
+					if utf16.IsSurrogate(rune(r)) && i+6 < len(s) && s[i+1:i+3] == `\u` {
+						if r2, err := strconv.ParseUint(s[i+3:i+7], 16, 32); err == nil {
+							b.WriteRune(utf16.DecodeRune(rune(r), rune(r2)))
+							i += 6
+							continue
+						}
+					}
+					b.WriteRune(rune(r))
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseProperties_JavaSemantics(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "application.properties")
+	os.WriteFile(file, []byte(`# comment
+! bang comment
+spring.datasource.url=jdbc:postgresql://db:5432/app
+server.port: 8080
+kafka.topic orders
+fruits = apple, banana, \
+         pear
+greeting = caf\u00e9 \uD83D\uDE00
+path = C:\\temp\\app
+key\ with\ spaces = spaced
+tab = a\tb
+empty
+server.port = 9090
+`), 0644)
+
+	result, format := ParseFile(file)
+	if format != "properties" {
+		t.Errorf("Expected format properties, got %s", format)
+	}
+	expected := map[string]interface{}{
+		"spring.datasource.url": "jdbc:postgresql://db:5432/app",
+		"server.port":           "9090",
+		"kafka.topic":           "orders",
+		"fruits":                "apple, banana, pear",
+		"greeting":              "café 😀",
+		"path":                  `C:\temp\app`,
+		"key with spaces":       "spaced",
+		"tab":                   "a\tb",
+		"empty":                 "",
+	}
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("Expected %s = %q, got %q", k, v, result[k])
+		}
+	}
+	if len(result) != len(expected) {
+		t.Errorf("Expected %d keys, got %d: %v", len(expected), len(result), result)
+	}
+}
@@ -1,27 +1,61 @@
 package parser
 
 import (
-	"os"
+	"io"
 	"strings"
 )
 
 func ParseText(path string) map[string]interface{} {
-	data, err := os.ReadFile(path)
+	return parsePath(textParser{}, path)
+}
+
+// textParser is the catch-all for plain key=value lines. Its patterns are
+// generic names (.txt, *config, tool rc files) whose content is sniffed
+// before falling back to key=value text.
+type textParser struct{}
+
+func (textParser) Name() string { return "text" }
+
+func (textParser) Untyped() bool { return true }
+
+func (textParser) Patterns() []string {
+	return []string{".txt", "config", ".babelrc", ".eslintrc", ".prettierrc", ".npmrc"}
+}
+
+// Sniff always gives text a small chance so it remains the last resort.
+func (textParser) Sniff(data []byte) float64 {
+	if strings.Contains(string(data), "=") {
+		return 0.1
+	}
+	return 0.01
+}
+
+func (p textParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	settings, _, err := p.ParsePositions(r)
+	return settings, err
+}
+
+func (textParser) ParsePositions(r io.Reader) (map[string]interface{}, map[string]Position, error) {
+	data, err := io.ReadAll(r)
 	if err != nil {
-		return map[string]interface{}{"error": err.Error()}
+		return nil, nil, err
 	}
 
 	lines := strings.Split(string(data), "\n")
 	kv := make(map[string]interface{})
+	positions := make(map[string]Position)
 
-	for _, line := range lines {
+	for n, line := range lines {
 		if strings.Contains(line, "=") {
 			parts := strings.SplitN(line, "=", 2)
 			key := strings.TrimSpace(parts[0])
 			val := strings.TrimSpace(parts[1])
-			kv[key] = val
+			if key != "" {
+				kv[key] = val
+				positions[key] = Position{Line: n + 1, Column: len(parts[0]) - len(strings.TrimLeft(parts[0], " \t")) + 1}
+			}
 		}
 	}
 
-	return kv
+	return kv, positions, nil
 }
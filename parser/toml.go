@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"io"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+func ParseTOML(path string) map[string]interface{} {
+	return parsePath(tomlParser{}, path)
+}
+
+type tomlParser struct{}
+
+func (tomlParser) Name() string { return "toml" }
+
+func (tomlParser) Patterns() []string { return []string{".toml"} }
+
+func (tomlParser) Sniff(data []byte) float64 {
+	if looksLikeTOML(string(data)) {
+		return 0.6
+	}
+	return 0
+}
+
+func (p tomlParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	settings, _, err := p.decode(r)
+	return settings, err
+}
+
+// ParseOrder lists the settings in the order of the keys the decoder
+// reports, each followed by the elements of the array it holds.
+func (p tomlParser) ParseOrder(r io.Reader) ([]string, error) {
+	settings, md, err := p.decode(r)
+	if err != nil {
+		return nil, err
+	}
+	// array elements, by the key of each array they are nested in
+	elements := make(map[string]map[string]interface{})
+	for key, v := range settings {
+		for i := strings.IndexByte(key, '['); i > 0; {
+			prefix := key[:i]
+			if elements[prefix] == nil {
+				elements[prefix] = make(map[string]interface{})
+			}
+			elements[prefix][key] = v
+			next := strings.IndexByte(key[i+1:], '[')
+			if next < 0 {
+				break
+			}
+			i += 1 + next
+		}
+	}
+
+	var order []string
+	// [[table]] headers seen so far, by flattened key
+	tables := make(map[string]int)
+	for _, k := range md.Keys() {
+		name := ""
+		for i := range k {
+			name = joinKey(name, k[i])
+			if md.Type(k[:i+1]...) != "ArrayHash" {
+				continue
+			}
+			if i == len(k)-1 {
+				tables[name]++
+			}
+			if n := tables[name]; n > 0 {
+				name = indexKey(name, n-1)
+			}
+		}
+		order = append(order, name)
+		if group, ok := elements[name]; ok {
+			order = append(order, sourceOrder(group, nil)...)
+			delete(elements, name)
+		}
+	}
+	return order, nil
+}
+
+func (tomlParser) decode(r io.Reader) (map[string]interface{}, toml.MetaData, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, toml.MetaData{}, err
+	}
+	var result map[string]interface{}
+	md, err := toml.Decode(string(data), &result)
+	if err != nil {
+		return nil, md, err
+	}
+	return flatten(result, ""), md, nil
+}
+
+// looksLikeTOML reports whether text carries a TOML-only signal: a [table] or
+// [[array]] header, or a key assigned a quoted string, array or inline table.
+// Plain KEY=value lines are left to the text parser.
+func looksLikeTOML(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") && !strings.Contains(line, ",") {
+			return true
+		}
+		if i := strings.Index(line, "="); i > 0 {
+			v := strings.TrimSpace(line[i+1:])
+			if strings.HasPrefix(v, `"`) || strings.HasPrefix(v, "'") || strings.HasPrefix(v, "[") || strings.HasPrefix(v, "{") {
+				return true
+			}
+		}
+	}
+	return false
+}
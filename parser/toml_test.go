@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTOML_TablesAndArrays(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Cargo.toml")
+	os.WriteFile(file, []byte(`
+title = "demo"
+
+[server]
+port = 8080
+tls = { enabled = true, cert = "a.pem" }
+
+[[products]]
+name = "hammer"
+
+[[products]]
+name = "nail"
+sku = 284758393
+`), 0644)
+
+	result, format := ParseFile(file)
+	if format != "toml" {
+		t.Errorf("Expected format toml, got %s", format)
+	}
+	expected := map[string]interface{}{
+		"title":              "demo",
+		"server.port":        int64(8080),
+		"server.tls.enabled": true,
+		"server.tls.cert":    "a.pem",
+		"products[0].name":   "hammer",
+		"products[1].name":   "nail",
+		"products[1].sku":    int64(284758393),
+	}
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("Expected %s = %v, got %v", k, v, result[k])
+		}
+	}
+}
+
+func TestParseBytes_TOML(t *testing.T) {
+	result, format := ParseBytes([]byte("[database]\nurl = \"postgres://db:5432\"\n"))
+	if format != "toml" {
+		t.Errorf("Expected format toml, got %s", format)
+	}
+	if result["database.url"] != "postgres://db:5432" {
+		t.Errorf("Expected database.url to be parsed, got %v", result)
+	}
+
+	_, format = ParseBytes([]byte("MODE=prod\nLOG_LEVEL=info\n"))
+	if format != "text" {
+		t.Errorf("Expected plain key=value to stay text, got %s", format)
+	}
+}
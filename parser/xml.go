@@ -1,21 +1,189 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/xml"
-	"os"
+	"fmt"
+	"io"
+	"strings"
 )
 
 func ParseXML(path string) map[string]interface{} {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return map[string]interface{}{"error": err.Error()}
+	return parsePath(xmlParser{}, path)
+}
+
+type xmlParser struct{}
+
+func (xmlParser) Name() string { return "xml" }
+
+func (xmlParser) Untyped() bool { return true }
+
+func (xmlParser) Patterns() []string { return []string{".xml", ".config"} }
+
+func (xmlParser) Sniff(data []byte) float64 {
+	text := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(text, "<?xml"):
+		return 0.95
+	case looksLikeXML(text):
+		return 0.9
 	}
+	return 0
+}
 
-	var result interface{}
-	err = xml.Unmarshal(data, &result)
+func (p xmlParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	settings, _, err := p.ParsePositions(r)
+	return settings, err
+}
+
+func (xmlParser) ParsePositions(r io.Reader) (map[string]interface{}, map[string]Position, error) {
+	data, err := io.ReadAll(r)
 	if err != nil {
-		return map[string]interface{}{"error": err.Error()}
+		return nil, nil, err
 	}
+	return parseXML(data)
+}
+
+// xmlNode is an element read from an XML document.
+type xmlNode struct {
+	name     string
+	at       Position
+	attrs    []xml.Attr
+	text     strings.Builder
+	children []*xmlNode
+}
+
+// parseXML flattens an XML document into dot-separated element paths rooted
+// at the document element. Attributes become "@name" keys, repeated sibling
+// elements are indexed like lists (add[0], add[1]), and text content is
+// stored on the element's own key, or under "#text" when the element also
+// has attributes or children. Namespaced names keep the prefix used in the
+// document. Every key is positioned at the start tag of its element.
+func parseXML(data []byte) (map[string]interface{}, map[string]Position, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	// data is already UTF-8 whatever the declaration says
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) { return input, nil }
+
+	var root *xmlNode
+	var stack []*xmlNode
+	prefixes := []map[string]string{{"http://www.w3.org/XML/1998/namespace": "xml"}}
+	lines := newLineIndex(data)
+
+	for {
+		off := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			scope := make(map[string]string)
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" {
+					scope[a.Value] = a.Name.Local
+				} else if a.Name.Space == "" && a.Name.Local == "xmlns" {
+					scope[a.Value] = ""
+				}
+			}
+			prefixes = append(prefixes, scope)
+
+			// the token may start after whitespace already consumed as text
+			if i := bytes.IndexByte(data[off:], '<'); i >= 0 {
+				off += int64(i)
+			}
+			n := &xmlNode{name: xmlName(t.Name, prefixes), at: lines.position(off)}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+					continue
+				}
+				a.Name.Local = xmlName(a.Name, prefixes)
+				n.attrs = append(n.attrs, a)
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+				prefixes = prefixes[:len(prefixes)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, nil, fmt.Errorf("no root element found")
+	}
+
+	flat := make(map[string]interface{})
+	positions := make(map[string]Position)
+	flattenXML(root, root.name, flat, positions)
+	return flat, positions, nil
+}
+
+// xmlName renders a name with the prefix declared for its namespace, falling
+// back to the raw prefix when the namespace was never declared.
+func xmlName(name xml.Name, prefixes []map[string]string) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		if prefix, ok := prefixes[i][name.Space]; ok {
+			if prefix == "" {
+				return name.Local
+			}
+			return prefix + ":" + name.Local
+		}
+	}
+	return name.Space + ":" + name.Local
+}
+
+func flattenXML(n *xmlNode, key string, flat map[string]interface{}, positions map[string]Position) {
+	for _, a := range n.attrs {
+		flat[key+".@"+a.Name.Local] = a.Value
+		positions[key+".@"+a.Name.Local] = n.at
+	}
+
+	text := strings.TrimSpace(n.text.String())
+	if text != "" {
+		if len(n.attrs) == 0 && len(n.children) == 0 {
+			flat[key] = text
+			positions[key] = n.at
+		} else {
+			flat[key+".#text"] = text
+			positions[key+".#text"] = n.at
+		}
+	} else if len(n.attrs) == 0 && len(n.children) == 0 {
+		flat[key] = ""
+		positions[key] = n.at
+	}
+
+	counts := make(map[string]int)
+	for _, c := range n.children {
+		counts[c.name]++
+	}
+	seen := make(map[string]int)
+	for _, c := range n.children {
+		childKey := key + "." + c.name
+		if counts[c.name] > 1 {
+			childKey = indexKey(childKey, seen[c.name])
+			seen[c.name]++
+		}
+		flattenXML(c, childKey, flat, positions)
+	}
+}
 
-	return map[string]interface{}{"parsed_xml": result} // XML is trickier to flatten without schema
+// looksLikeXML reports whether text starts with a tag and ends with one.
+func looksLikeXML(text string) bool {
+	text = strings.TrimSpace(text)
+	return strings.HasPrefix(text, "<") && strings.HasSuffix(text, ">")
 }
@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseXML_Flatten(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "web.config")
+	os.WriteFile(file, []byte(`<?xml version="1.0" encoding="utf-8"?>
+<configuration xmlns:xdt="http://schemas.microsoft.com/XML-Document-Transform">
+  <connectionStrings>
+    <add name="Main" connectionString="Server=db;Database=app" xdt:Transform="Replace"/>
+  </connectionStrings>
+  <appSettings>
+    <add key="Mode" value="prod"/>
+    <add key="Debug" value="false"/>
+  </appSettings>
+  <log level="warn">rolling</log>
+  <owner>ops</owner>
+</configuration>`), 0644)
+
+	result, format := ParseFile(file)
+	if format != "xml" {
+		t.Errorf("Expected format xml, got %s", format)
+	}
+	expected := map[string]interface{}{
+		"configuration.connectionStrings.add.@connectionString": "Server=db;Database=app",
+		"configuration.connectionStrings.add.@xdt:Transform":    "Replace",
+		"configuration.appSettings.add[0].@key":                 "Mode",
+		"configuration.appSettings.add[1].@value":               "false",
+		"configuration.log.@level":                              "warn",
+		"configuration.log.#text":                               "rolling",
+		"configuration.owner":                                   "ops",
+	}
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("Expected %s = %q, got %q", k, v, result[k])
+		}
+	}
+}
@@ -1,22 +1,246 @@
 package parser
 
 import (
-	"os"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 func ParseYAML(path string) map[string]interface{} {
-	data, err := os.ReadFile(path)
+	return parsePath(yamlParser{}, path)
+}
+
+type yamlParser struct{}
+
+func (yamlParser) Name() string { return "yaml" }
+
+func (yamlParser) Patterns() []string { return []string{".yaml", ".yml"} }
+
+func (yamlParser) Sniff(data []byte) float64 {
+	text := strings.TrimSpace(string(data))
+	if !strings.Contains(text, ":") && !strings.HasPrefix(text, "- ") {
+		return 0
+	}
+	// a bare scalar is better shown as raw text
+	if m, _, err := parseYAML(data); err != nil || len(m) == 0 || isScalarRoot(m) {
+		return 0
+	}
+	return 0.5
+}
+
+func (p yamlParser) Parse(r io.Reader) (map[string]interface{}, error) {
+	settings, _, err := p.ParsePositions(r)
+	return settings, err
+}
+
+func (yamlParser) ParsePositions(r io.Reader) (map[string]interface{}, map[string]Position, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseYAML(data)
+}
+
+func (yamlParser) ParseComments(r io.Reader) (map[string]Comment, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	nodes, _, prefixes, err := yamlStream(data)
+	if err != nil {
+		return nil, err
+	}
+	return yamlComments(nodes, prefixes), nil
+}
+
+// readDocument is ParsePositions and ParseComments from a single decode.
+func (yamlParser) readDocument(data []byte) (map[string]interface{}, map[string]Position, map[string]Comment, error) {
+	nodes, docs, prefixes, err := yamlStream(data)
 	if err != nil {
-		return map[string]interface{}{"error": err.Error()}
+		return nil, nil, nil, err
 	}
+	flat, positions := flattenYAML(nodes, docs, prefixes)
+	return flat, positions, yamlComments(nodes, prefixes), nil
+}
 
-	var result map[string]interface{}
-	err = yaml.Unmarshal(data, &result)
+// parseYAML flattens every document in a YAML stream and records where each
+// key is defined. A single document is flattened as-is, whatever its root
+// type; when there are several, each document's keys are prefixed with
+// "Kind/name" if it looks like a Kubernetes object, or its index in the
+// stream (doc[1]) otherwise. Empty documents are skipped.
+func parseYAML(data []byte) (map[string]interface{}, map[string]Position, error) {
+	nodes, docs, prefixes, err := yamlStream(data)
 	if err != nil {
-		return map[string]interface{}{"error": err.Error()}
+		return nil, nil, err
 	}
+	flat, positions := flattenYAML(nodes, docs, prefixes)
+	return flat, positions, nil
+}
 
-	return flatten(result, "")
+// flattenYAML is parseYAML on a decoded stream.
+func flattenYAML(nodes []*yaml.Node, docs []interface{}, prefixes []string) (map[string]interface{}, map[string]Position) {
+	positions := make(map[string]Position)
+	if len(docs) == 1 {
+		flat := flattenRoot(docs[0])
+		yamlPositions(nodes[0], "", positions)
+		positions[RootKey] = positions[""]
+		return flat, keepPositions(positions, flat)
+	}
+
+	flat := make(map[string]interface{})
+	for i, doc := range docs {
+		flattenValue(doc, prefixes[i], flat)
+		yamlPositions(nodes[i], prefixes[i], positions)
+	}
+	return flat, keepPositions(positions, flat)
+}
+
+// yamlComments collects the comments above and after each key of a decoded
+// stream.
+func yamlComments(nodes []*yaml.Node, prefixes []string) map[string]Comment {
+	comments := make(map[string]Comment)
+	for i, n := range nodes {
+		yamlWalk(n, prefixes[i], func(key string, at, value *yaml.Node) {
+			c := Comment{
+				Leading:  commentText(at.HeadComment, "#"),
+				Trailing: commentText(at.LineComment, "#"),
+			}
+			if c.Trailing == "" {
+				c.Trailing = commentText(value.LineComment, "#")
+			}
+			comments[key] = c
+		})
+	}
+	return comments
+}
+
+// yamlStream decodes the non-empty documents of a YAML stream, as nodes and
+// as values, with the key prefix of each: "" for a lone document, otherwise
+// "Kind/name" or doc[i].
+func yamlStream(data []byte) ([]*yaml.Node, []interface{}, []string, error) {
+	var nodes []*yaml.Node
+	var docs []interface{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		node := new(yaml.Node)
+		err := dec.Decode(node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var doc interface{}
+		if err := node.Decode(&doc); err != nil {
+			return nil, nil, nil, err
+		}
+		if doc != nil {
+			nodes = append(nodes, node)
+			docs = append(docs, doc)
+		}
+	}
+
+	prefixes := make([]string, len(docs))
+	if len(docs) == 1 {
+		return nodes, docs, prefixes, nil
+	}
+	used := make(map[string]bool)
+	for i, doc := range docs {
+		prefix := kubernetesObjectName(doc)
+		if prefix == "" || used[prefix] {
+			prefix = indexKey("doc", i)
+		}
+		used[prefix] = true
+		prefixes[i] = prefix
+	}
+	return nodes, docs, prefixes, nil
+}
+
+// yamlPositions records where n and everything below it is defined, using
+// the same key names as flattenValue. Map entries are placed at their key,
+// list items at the item itself.
+func yamlPositions(n *yaml.Node, key string, positions map[string]Position) {
+	positions[key] = Position{Line: n.Line, Column: n.Column}
+	yamlWalk(n, key, func(key string, at, _ *yaml.Node) {
+		positions[key] = Position{Line: at.Line, Column: at.Column}
+	})
+}
+
+// yamlWalk calls visit for every map entry and list item below n, keyed like
+// flattenValue. at is the node that defines the key (the map key, or the
+// list item itself) and value its value. Aliases are followed, and entries
+// merged in with << are visited before the explicit keys that override them.
+func yamlWalk(n *yaml.Node, key string, visit func(key string, at, value *yaml.Node)) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			yamlWalk(c, key, visit)
+		}
+	case yaml.AliasNode:
+		if n.Alias != nil {
+			yamlWalk(n.Alias, key, visit)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Tag == "!!merge" {
+				yamlWalkMerge(n.Content[i+1], key, visit)
+			}
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				continue
+			}
+			child := joinKey(key, k.Value)
+			visit(child, k, v)
+			yamlWalk(v, child, visit)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			child := indexKey(key, i)
+			visit(child, item, item)
+			yamlWalk(item, child, visit)
+		}
+	}
+}
+
+// yamlWalkMerge handles the value of a << merge key: a mapping, an alias to
+// one, or a list of them.
+func yamlWalkMerge(n *yaml.Node, key string, visit func(key string, at, value *yaml.Node)) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		if n.Alias != nil {
+			yamlWalkMerge(n.Alias, key, visit)
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			yamlWalkMerge(c, key, visit)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			child := joinKey(key, k.Value)
+			visit(child, k, v)
+			yamlWalk(v, child, visit)
+		}
+	}
+}
+
+// kubernetesObjectName returns "Kind/name" for documents carrying kind and
+// metadata.name, or "" for anything else.
+func kubernetesObjectName(doc interface{}) string {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	kind, _ := m["kind"].(string)
+	meta, _ := m["metadata"].(map[string]interface{})
+	name, _ := meta["name"].(string)
+	if kind == "" || name == "" {
+		return ""
+	}
+	return kind + "/" + name
 }
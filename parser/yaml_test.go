@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseYAML_MultiDocument(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "manifests.yaml")
+	os.WriteFile(file, []byte(`---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+    - port: 80
+---
+# just settings
+logLevel: debug
+---
+`), 0644)
+
+	result, format := ParseFile(file)
+	if format != "yaml" {
+		t.Errorf("Expected format yaml, got %s", format)
+	}
+	expected := map[string]interface{}{
+		"Deployment/web.spec.replicas":   3,
+		"Service/web.spec.ports[0].port": 80,
+		"doc[2].logLevel":                "debug",
+	}
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("Expected %s = %v, got %v", k, v, result[k])
+		}
+	}
+}
+
+func TestParseYAML_SingleDocumentUnprefixed(t *testing.T) {
+	result, _, err := parseYAML([]byte("server:\n  port: 8080\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result["server.port"] != 8080 {
+		t.Errorf("Expected server.port = 8080, got %v", result)
+	}
+}
@@ -0,0 +1,71 @@
+package scanner_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"Konfetti/parser"
+	"Konfetti/scanner"
+)
+
+// benchTree writes a tree of YAML, JSON and INI configs a few hundred
+// settings long, like a directory of service configs.
+func benchTree(b *testing.B) string {
+	dir := b.TempDir()
+	var yml, ini, js strings.Builder
+	js.WriteString("{")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&yml, "service%d:\n  host: host%d.example.com\n  port: %d\n", i, i, 8000+i)
+		fmt.Fprintf(&ini, "[service%d]\nhost = host%d.example.com\nport = %d\n", i, i, 8000+i)
+		if i > 0 {
+			js.WriteString(",")
+		}
+		fmt.Fprintf(&js, `"service%d": {"host": "host%d.example.com", "port": %d}`, i, i, 8000+i)
+	}
+	js.WriteString("}")
+
+	for d := 0; d < 20; d++ {
+		sub := filepath.Join(dir, fmt.Sprintf("service%02d", d))
+		os.MkdirAll(sub, 0755)
+		for f := 0; f < 5; f++ {
+			os.WriteFile(filepath.Join(sub, fmt.Sprintf("config%02d.yaml", f)), []byte(yml.String()), 0644)
+			os.WriteFile(filepath.Join(sub, fmt.Sprintf("config%02d.json", f)), []byte(js.String()), 0644)
+			os.WriteFile(filepath.Join(sub, fmt.Sprintf("config%02d.ini", f)), []byte(ini.String()), 0644)
+		}
+	}
+	return dir
+}
+
+// BenchmarkScanSequential walks the tree, then parses each file in turn.
+func BenchmarkScanSequential(b *testing.B) {
+	dir := benchTree(b)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		files, _ := scanner.ScanDirs([]string{dir}, parser.Patterns())
+		for _, f := range files {
+			parser.ParseContext(ctx, f, parser.Options{})
+		}
+	}
+}
+
+// BenchmarkProcess parses the same tree on jobs workers fed by the walk, as
+// -jobs does; compare its ns/op against BenchmarkScanSequential.
+func BenchmarkProcess(b *testing.B) {
+	dir := benchTree(b)
+	ctx := context.Background()
+	for _, jobs := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				scanner.Process(ctx, []string{dir}, parser.Patterns(), scanner.Options{}, jobs, func(path string) error {
+					_, err := parser.ParseContext(ctx, path, parser.Options{})
+					return err
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !unix
+
+package scanner
+
+import "os"
+
+// fileID tells files apart whatever path reaches them. Without device and
+// inode numbers it is the resolved path, so hard links are not recognized.
+type fileID struct {
+	path string
+}
+
+// identify returns the identity of the file at p, which info describes
+// with links followed.
+func identify(p string, info os.FileInfo) (fileID, bool) {
+	resolved := resolvePath(p)
+	return fileID{resolved}, resolved != ""
+}
+
+// linkCount returns how many hard links info's file has, which is not known
+// here.
+func linkCount(info os.FileInfo) uint64 {
+	return 1
+}
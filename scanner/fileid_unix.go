@@ -0,0 +1,31 @@
+//go:build unix
+
+package scanner
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID tells files apart whatever path reaches them.
+type fileID struct {
+	dev, ino uint64
+}
+
+// identify returns the identity of the file at p, which info describes
+// with links followed.
+func identify(p string, info os.FileInfo) (fileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{uint64(st.Dev), uint64(st.Ino)}, true
+}
+
+// linkCount returns how many hard links info's file has.
+func linkCount(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Nlink)
+	}
+	return 1
+}
@@ -0,0 +1,160 @@
+package scanner
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile names the file listing, in .gitignore syntax, what Walk skips in
+// the directory holding it and everything below.
+const IgnoreFile = ".konfettiignore"
+
+// DefaultExcludes are the usual Options.Exclude patterns: version control
+// metadata, dependency trees and build output.
+var DefaultExcludes = []string{
+	".git/", ".hg/", ".svn/",
+	"node_modules/", "bower_components/", "vendor/",
+	".venv/", "venv/", "__pycache__/", ".tox/", ".terraform/",
+	"dist/", "build/", "target/",
+}
+
+// ignoreRule is one pattern line of an ignore file or of Exclude.
+type ignoreRule struct {
+	base     string // directory the pattern is relative to
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+// parseIgnoreRule reads a .gitignore line, returning false for blank lines
+// and comments.
+func parseIgnoreRule(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	rule := ignoreRule{base: filepath.Clean(base)}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	// only a slash before the end anchors the pattern to base
+	if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	rule.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	return rule, true
+}
+
+func (r ignoreRule) match(p string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	rel, err := filepath.Rel(r.base, p)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return false
+	}
+	return matchSegments(r.segments, strings.Split(rel, "/"))
+}
+
+// matchSegments matches a slash-separated path against a pattern split the
+// same way, where a "**" segment stands for any number of segments.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ignorer decides what Walk skips below one scanned path, from the Exclude
+// option and the ignore files of the directories walked so far.
+type ignorer struct {
+	root      string
+	gitIgnore bool
+	rules     map[string][]ignoreRule // by the directory they apply below
+}
+
+func newIgnorer(root string, opts Options) *ignorer {
+	ig := &ignorer{root: filepath.Clean(root), gitIgnore: opts.GitIgnore, rules: make(map[string][]ignoreRule)}
+	for _, pattern := range opts.Exclude {
+		if rule, ok := parseIgnoreRule(ig.root, pattern); ok {
+			ig.rules[ig.root] = append(ig.rules[ig.root], rule)
+		}
+	}
+	return ig
+}
+
+// enter loads the ignore files of dir, before anything in it is checked.
+func (ig *ignorer) enter(dir string) {
+	dir = filepath.Clean(dir)
+	names := []string{IgnoreFile}
+	if ig.gitIgnore {
+		names = []string{".gitignore", IgnoreFile}
+	}
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if rule, ok := parseIgnoreRule(dir, line); ok {
+				ig.rules[dir] = append(ig.rules[dir], rule)
+			}
+		}
+	}
+}
+
+// ignored applies the rules of every directory from the root down to p's
+// parent in turn; as in git, the last matching rule wins, so deeper ignore
+// files and later "!" lines override earlier ones.
+func (ig *ignorer) ignored(p string, isDir bool) bool {
+	p = filepath.Clean(p)
+	if p == ig.root {
+		return false
+	}
+	var dirs []string
+	for d := filepath.Dir(p); ; d = filepath.Dir(d) {
+		dirs = append(dirs, d)
+		if d == ig.root || d == filepath.Dir(d) {
+			break
+		}
+	}
+	ignored := false
+	for i := len(dirs) - 1; i >= 0; i-- {
+		for _, rule := range ig.rules[dirs[i]] {
+			if rule.match(p, isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,133 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeTree creates files (with content when given) under a new directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), 0755)
+		os.WriteFile(file, []byte(content), 0644)
+	}
+	return dir
+}
+
+// scanRel returns the files ScanDirsContext finds in dir with opts, relative
+// to it.
+func scanRel(t *testing.T, dir string, opts Options, extensions ...string) []string {
+	t.Helper()
+	files, errs := ScanDirsContext(context.Background(), []string{dir}, extensions, opts)
+	if len(errs) != 0 {
+		t.Errorf("Expected 0 errors, got %v", errs)
+	}
+	var rel []string
+	for _, f := range files {
+		r, _ := filepath.Rel(dir, f)
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	sort.Strings(rel)
+	return rel
+}
+
+func TestScanDirs_DefaultExcludes(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"package.json":                       "{}",
+		"node_modules/left-pad/package.json": "{}",
+		".git/config.json":                   "{}",
+		"src/vendor/mod.json":                "{}",
+		"build":                              "not a directory",
+	})
+
+	if got := scanRel(t, dir, Options{Exclude: DefaultExcludes}, ".json"); !reflect.DeepEqual(got, []string{"package.json"}) {
+		t.Errorf("Expected dependency and VCS directories to be skipped, got %v", got)
+	}
+	if got := scanRel(t, dir, Options{}, ".json"); len(got) != 4 {
+		t.Errorf("Expected every file without default excludes, got %v", got)
+	}
+}
+
+func TestScanDirs_Exclude(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"app.json":              "{}",
+		"app.bak.json":          "{}",
+		"testdata/fixture.json": "{}",
+		"src/testdata/x.json":   "{}",
+		"docs/api/spec.json":    "{}",
+	})
+
+	opts := Options{Exclude: []string{"*.bak.json", "/testdata", "docs/**/spec.json"}}
+	expected := []string{"app.json", "src/testdata/x.json"}
+	if got := scanRel(t, dir, opts, ".json"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestScanDirs_IgnoreFiles(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		".gitignore":            "*.local.json\n!keep.local.json\n/generated/\n",
+		"app.json":              "{}",
+		"dev.local.json":        "{}",
+		"keep.local.json":       "{}",
+		"generated/out.json":    "{}",
+		"sub/.gitignore":        "# nested\nsecret.json\n",
+		"sub/secret.json":       "{}",
+		"sub/generated/x.json":  "{}",
+		"tools/.konfettiignore": "*.json\n!tool.json\n",
+		"tools/tool.json":       "{}",
+		"tools/other.json":      "{}",
+	})
+
+	expected := []string{"app.json", "dev.local.json", "generated/out.json", "keep.local.json", "sub/generated/x.json", "sub/secret.json", "tools/tool.json"}
+	if got := scanRel(t, dir, Options{}, ".json"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected only .konfettiignore to apply by default: expected %v, got %v", expected, got)
+	}
+
+	expected = []string{"app.json", "keep.local.json", "sub/generated/x.json", "tools/tool.json"}
+	if got := scanRel(t, dir, Options{GitIgnore: true}, ".json"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected .gitignore rules to apply: expected %v, got %v", expected, got)
+	}
+}
+
+func TestIgnoreRule_Match(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		match   bool
+	}{
+		{"*.log", "a/b/debug.log", false, true},
+		{"logs/", "logs", true, true},
+		{"logs/", "logs", false, false},
+		{"/config.json", "config.json", false, true},
+		{"/config.json", "sub/config.json", false, false},
+		{"a/*/c", "a/b/c", false, true},
+		{"a/**/c", "a/c", false, true},
+		{"a/**/c", "a/x/y/c", false, true},
+		{"**/fixtures", "x/fixtures", true, true},
+		{`\#notes`, "#notes", false, true},
+	}
+	for _, c := range cases {
+		rule, ok := parseIgnoreRule("/base", c.pattern)
+		if !ok {
+			t.Errorf("%q: expected a rule", c.pattern)
+			continue
+		}
+		if got := rule.match(filepath.Join("/base", c.path), c.isDir); got != c.match {
+			t.Errorf("%q vs %q: expected %v, got %v", c.pattern, c.path, c.match, got)
+		}
+	}
+	for _, line := range []string{"", "# comment", "   ", "/"} {
+		if _, ok := parseIgnoreRule("/base", line); ok {
+			t.Errorf("%q: expected no rule", line)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package scanner
+
+import (
+	"context"
+	"sync"
+)
+
+// Process runs fn over every file ScanDirsContext would return with opts, on
+// jobs goroutines fed while the directories are still being walked. The
+// results come back in walk order whatever order the workers finish in,
+// along with the walk errors. jobs below 1 means 1. When ctx is done the results so far are
+// returned, see Stream.
+func Process[T any](ctx context.Context, paths []string, extensions []string, opts Options, jobs int, fn func(path string) T) ([]T, []string) {
+	var results []T
+	errors := Stream(ctx, paths, extensions, opts, jobs, fn, func(result T) {
+		results = append(results, result)
+	}, nil)
+	return results, errors
+}
+
+// Stream is Process handing each result to emit as soon as it and the
+// results of every file walked before it are ready, instead of collecting
+// them. emit is called from one goroutine at a time. Only a few results per
+// worker are held back waiting for a slower earlier file, so memory does not
+// grow with the number of files unless the walk has to remember them, see
+// Walk.
+//
+// A file reached again by another path is not processed twice; alias, when
+// not nil, is called with that path and the first one, like emit from one
+// goroutine at a time but in walk order rather than after the file's result.
+//
+// Once ctx is done Stream returns without waiting for the walk or for fn
+// calls still running, which should watch ctx themselves, along with the
+// walk errors met so far; emit and alias are not called after it returns.
+func Stream[T any](ctx context.Context, paths []string, extensions []string, opts Options, jobs int, fn func(path string) T, emit func(T), alias func(path, first string)) []string {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	type job struct {
+		index int
+		path  string
+	}
+	type done struct {
+		index  int
+		result T
+	}
+	queue := make(chan job)
+	finished := make(chan done)
+	// a slot is taken per file from queueing until it is emitted
+	slots := make(chan struct{}, jobs*4)
+
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				finished <- done{j.index, fn(j.path)}
+			}
+		}()
+	}
+
+	// emitMu keeps emit and alias from running at once, and once Stream has
+	// returned on cancel
+	var emitMu sync.Mutex
+	stopped := false
+	emitted := make(chan struct{})
+	go func() {
+		defer close(emitted)
+		pending := make(map[int]T)
+		next := 0
+		for d := range finished {
+			pending[d.index] = d.result
+			for {
+				result, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				emitMu.Lock()
+				if !stopped {
+					emit(result)
+				}
+				emitMu.Unlock()
+				next++
+				<-slots
+			}
+		}
+	}()
+
+	var onAlias func(path, first string)
+	if alias != nil {
+		onAlias = func(path, first string) {
+			emitMu.Lock()
+			defer emitMu.Unlock()
+			if !stopped {
+				alias(path, first)
+			}
+		}
+	}
+
+	// errMu guards errors, which the walk adds to while Stream may return
+	var errMu sync.Mutex
+	errors := make([]string, 0)
+	warn := func(msg string) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		errors = append(errors, msg)
+	}
+
+	walked := make(chan struct{})
+	go func() {
+		n := 0
+		walkPaths(ctx, paths, extensions, opts, func(path string) {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case queue <- job{n, path}:
+				n++
+			case <-ctx.Done():
+				<-slots
+			}
+		}, onAlias, warn)
+		close(queue)
+		wg.Wait()
+		close(finished)
+		<-emitted
+		close(walked)
+	}()
+
+	select {
+	case <-walked:
+		return errors
+	case <-ctx.Done():
+	}
+	emitMu.Lock()
+	defer emitMu.Unlock()
+	select {
+	case <-walked:
+		// everything finished as ctx was cancelled
+		return errors
+	default:
+	}
+	stopped = true
+	errMu.Lock()
+	defer errMu.Unlock()
+	return append(make([]string, 0, len(errors)), errors...)
+}
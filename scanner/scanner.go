@@ -1,38 +1,74 @@
 package scanner
 
 import (
-	"os"
+	"context"
 	"path/filepath"
 	"strings"
 )
 
-// ScanDirs scans the provided directories for files with specified extensions.
+// ScanDirs scans the provided directories for files with specified extensions,
+// with zero Options: only ignore files rule anything out (see Walk).
+// An extension containing glob characters (e.g. ".env.*") is matched against the whole file name instead.
 // It returns a slice of file paths that match the given extensions, and a slice of error messages for any access errors encountered.
 func ScanDirs(paths []string, extensions []string) ([]string, []string) {
+	return ScanDirsContext(context.Background(), paths, extensions, Options{})
+}
+
+// ScanDirsContext is ScanDirs with opts, stopping once ctx is done and
+// returning the files found so far. Each file is listed once, however many
+// paths lead to it.
+func ScanDirsContext(ctx context.Context, paths []string, extensions []string, opts Options) ([]string, []string) {
 	configFiles := make([]string, 0)
+	errors := Walk(ctx, paths, extensions, opts, func(p string) {
+		configFiles = append(configFiles, p)
+	}, nil)
+	return configFiles, errors
+}
+
+// Walk is ScanDirsContext calling found for each matching file as soon as it
+// is reached, in the same order, instead of collecting them.
+//
+// Files and directories matching opts.Exclude or an IgnoreFile (and
+// .gitignore files with opts.GitIgnore) are skipped without being read.
+// opts.MaxDepth and opts.FollowSymlinks decide how far the walk goes. A file reached by more than one
+// path, through links or hard links, is passed to found under the first path
+// only; alias, when not nil, is called with each later path and that first
+// one. Only files that can be reached twice are remembered to tell: those
+// with several hard links, link targets, and with opts.FollowSymlinks or
+// overlapping paths every file.
+func Walk(ctx context.Context, paths []string, extensions []string, opts Options, found func(path string), alias func(path, first string)) []string {
 	errors := make([]string, 0)
+	walkPaths(ctx, paths, extensions, opts, found, alias, func(msg string) {
+		errors = append(errors, msg)
+	})
+	return errors
+}
 
-	for _, path := range paths {
-		filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
-			if err != nil {
-				errors = append(errors, p+": "+err.Error())
-				return nil
-			}
-			if info == nil {
-				return nil
-			}
-			if !info.IsDir() {
-				for _, ext := range extensions {
-					if strings.HasSuffix(strings.ToLower(info.Name()), ext) {
-						configFiles = append(configFiles, p)
-						break
-					}
-				}
+// walkPaths is Walk handing each error to warn as it happens.
+func walkPaths(ctx context.Context, paths []string, extensions []string, opts Options, found func(path string), alias func(path, first string), warn func(msg string)) {
+	w := &walker{
+		ctx:        ctx,
+		opts:       opts,
+		extensions: extensions,
+		found:      found,
+		alias:      alias,
+		warn:       warn,
+	}
+	w.run(paths)
+}
+
+func matchesExtension(name string, extensions []string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range extensions {
+		if strings.ContainsAny(ext, "*?[") {
+			if ok, _ := filepath.Match(ext, name); ok {
+				return true
 			}
-			return nil
-		})
+		} else if strings.HasSuffix(name, ext) {
+			return true
+		}
 	}
-	return configFiles, errors
+	return false
 }
 
 // ScanDirs scans the provided directories for files with specified extensions.
@@ -45,6 +81,6 @@ func ScanDirs(paths []string, extensions []string) ([]string, []string) {
 //// Example usage:
 //   files := scanner.ScanDirs([]string{"/etc", "/home/user/.config"}, []string{".json", ".yaml"})
 //   fmt.Println("Found config files:", files)
-//// This function uses filepath.Walk to traverse directories and checks each file's extension.
+//// This function walks the directory trees (see Walk) and checks each file's extension.
 // It collects paths of files that match the specified extensions and returns them as a slice.
 // It handles errors gracefully and skips directories that cannot be accessed.
@@ -1,9 +1,13 @@
 package scanner
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestScanDirs_Empty(t *testing.T) {
@@ -91,3 +95,135 @@ func TestScanDirs_SymlinkLoop(t *testing.T) {
 	// Should not hang or panic due to symlink loop
 	// Symlink loops may or may not produce errors depending on platform, so no strict error check here
 }
+
+func TestScanDirs_GlobExtensions(t *testing.T) {
+	dir := t.TempDir()
+	env := filepath.Join(dir, ".env")
+	envLocal := filepath.Join(dir, ".env.production")
+	other := filepath.Join(dir, "environment.md")
+	os.WriteFile(env, []byte("A=1"), 0644)
+	os.WriteFile(envLocal, []byte("A=2"), 0644)
+	os.WriteFile(other, []byte("# docs"), 0644)
+
+	files, _ := ScanDirs([]string{dir}, []string{".env", ".env.*"})
+	if len(files) != 2 {
+		t.Errorf("Expected 2 dotenv files, got %d: %v", len(files), files)
+	}
+}
+
+func TestProcess_KeepsWalkOrder(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json", "c/d.json", "c/e.json", "f.json", "skip.md"} {
+		file := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(file), 0755)
+		os.WriteFile(file, []byte(name), 0644)
+	}
+	expected, _ := ScanDirs([]string{dir}, []string{".json"})
+
+	for _, jobs := range []int{0, 1, 4} {
+		results, errs := Process(context.Background(), []string{dir}, []string{".json"}, Options{}, jobs, func(path string) string {
+			// finish out of order
+			if filepath.Base(path) == "a.json" {
+				time.Sleep(20 * time.Millisecond)
+			}
+			return path
+		})
+		if len(errs) != 0 {
+			t.Errorf("jobs=%d: expected 0 errors, got %v", jobs, errs)
+		}
+		if !reflect.DeepEqual(results, expected) {
+			t.Errorf("jobs=%d: expected %v, got %v", jobs, expected, results)
+		}
+	}
+}
+
+func TestProcess_Errors(t *testing.T) {
+	results, errs := Process(context.Background(), []string{"/unlikely/path/that/does/not/exist"}, []string{".json"}, Options{}, 2, func(path string) string {
+		return path
+	})
+	if len(results) != 0 {
+		t.Errorf("Expected 0 results, got %d", len(results))
+	}
+	if len(errs) == 0 {
+		t.Errorf("Expected at least one error for non-existent path")
+	}
+}
+
+func TestStream_EmitsBeforeAllFilesAreDone(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json", "z.json"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644)
+	}
+
+	first := make(chan struct{})
+	var emitted []string
+	errs := Stream(context.Background(), []string{dir}, []string{".json"}, Options{}, 2, func(path string) string {
+		if filepath.Base(path) == "z.json" {
+			// the last file only finishes once the first has been emitted
+			select {
+			case <-first:
+			case <-time.After(5 * time.Second):
+				t.Errorf("Expected a.json to be emitted while z.json was still being processed")
+			}
+		}
+		return filepath.Base(path)
+	}, func(name string) {
+		if len(emitted) == 0 {
+			close(first)
+		}
+		emitted = append(emitted, name)
+	}, nil)
+	if len(errs) != 0 {
+		t.Errorf("Expected 0 errors, got %v", errs)
+	}
+	if !reflect.DeepEqual(emitted, []string{"a.json", "b.json", "z.json"}) {
+		t.Errorf("Expected results in walk order, got %v", emitted)
+	}
+}
+
+func TestScanDirsContext_Cancelled(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.json"), []byte("{}"), 0644)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	files, errs := ScanDirsContext(ctx, []string{dir}, []string{".json"}, Options{})
+	if len(files) != 0 || len(errs) != 0 {
+		t.Errorf("Expected nothing once cancelled, got %v %v", files, errs)
+	}
+}
+
+func TestStream_ReturnsOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json", "c.json"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hung := make(chan struct{})
+	defer close(hung)
+	var emitted []string
+	start := time.Now()
+	missing := filepath.Join(dir, "missing")
+	errs := Stream(ctx, []string{missing, dir}, []string{".json"}, Options{}, 2, func(path string) string {
+		if filepath.Base(path) == "b.json" {
+			cancel()
+			// a file that never finishes on its own
+			<-hung
+		}
+		return filepath.Base(path)
+	}, func(name string) {
+		emitted = append(emitted, name)
+	}, nil)
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected Stream to return promptly once cancelled, took %s", time.Since(start))
+	}
+	for _, name := range emitted {
+		if name != "a.json" {
+			t.Errorf("Expected only results before the cancel, got %v", emitted)
+		}
+	}
+	if len(errs) != 1 || !strings.HasPrefix(errs[0], missing+": ") {
+		t.Errorf("Expected the walk errors met before the cancel, got %v", errs)
+	}
+}
@@ -0,0 +1,277 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Options decide which files Walk reaches. The zero value walks every
+// directory below each path without following links.
+type Options struct {
+	// Exclude holds .gitignore-style patterns of files and directories to
+	// skip, relative to each scanned path. A pattern without a slash, like
+	// "*.bak", matches at any depth. See DefaultExcludes.
+	Exclude []string
+	// GitIgnore honors .gitignore files as well as IgnoreFile ones.
+	GitIgnore bool
+	// MaxDepth limits how many directory levels below each scanned path are
+	// walked: 1 means only the files directly inside it. 0 means no limit.
+	MaxDepth int
+	// FollowSymlinks descends into symlinked directories, including a
+	// scanned path that is a link. A link back into a directory it is
+	// already inside of is not followed, so loops end.
+	FollowSymlinks bool
+}
+
+// walker is the state of one Walk call. Unlike filepath.Walk it can follow
+// links, and it reports each file once however many paths lead to it.
+type walker struct {
+	ctx        context.Context
+	opts       Options
+	extensions []string
+	found      func(path string)
+	alias      func(path, first string)
+	warn       func(msg string)
+	roots      []walkRoot
+	root       int // index in roots of the path being walked
+	// recordAll is set when any file may be reached twice: with
+	// FollowSymlinks, or when scanned paths overlap
+	recordAll bool
+	// the path each file that may be reached again was reported under:
+	// every file with recordAll, otherwise only hard-linked files and the
+	// targets of links
+	seen map[fileID]string
+}
+
+// walkRoot is one scanned path.
+type walkRoot struct {
+	path     string
+	resolved string // absolute, links resolved; "" if that failed
+	dir      bool   // walked as a directory
+	ig       *ignorer
+}
+
+func (w *walker) run(paths []string) {
+	w.seen = make(map[fileID]string)
+	w.roots = make([]walkRoot, len(paths))
+	for i, path := range paths {
+		w.roots[i] = walkRoot{path: path, resolved: resolvePath(path)}
+	}
+	w.recordAll = w.opts.FollowSymlinks || overlapping(w.roots)
+	for i := range w.roots {
+		if w.ctx.Err() != nil {
+			break
+		}
+		w.root = i
+		w.walkRoot(&w.roots[i])
+	}
+}
+
+func (w *walker) walkRoot(root *walkRoot) {
+	info, err := os.Lstat(root.path)
+	if err != nil {
+		w.warn(root.path + ": " + err.Error())
+		return
+	}
+	root.dir = info.IsDir()
+	root.ig = newIgnorer(root.path, w.opts)
+	w.walk(root.path, info, 0, nil, root.ig)
+}
+
+// resolvePath returns p made absolute with links resolved, or "".
+func resolvePath(p string) string {
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return ""
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return ""
+	}
+	return resolved
+}
+
+// overlapping reports whether a root is, or is inside, another one.
+func overlapping(roots []walkRoot) bool {
+	for i, a := range roots {
+		for j, b := range roots {
+			if i != j && a.resolved != "" && b.resolved != "" && relInside(a.resolved, b.resolved) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// relInside returns p relative to dir, "." for dir itself, or "" when p is
+// not inside it.
+func relInside(dir, p string) string {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return rel
+}
+
+// walk visits p, depth levels below the scanned path, inside the directories
+// ancestors (those reached through links included). info is from Lstat.
+func (w *walker) walk(p string, info os.FileInfo, depth int, ancestors []os.FileInfo, ig *ignorer) {
+	if w.ctx.Err() != nil {
+		return
+	}
+	if w.opts.FollowSymlinks && info.Mode()&os.ModeSymlink != 0 {
+		// a dangling link stays a link, and is reported like any file
+		if target, err := os.Stat(p); err == nil {
+			info = target
+		}
+	}
+	if ig.ignored(p, info.IsDir()) {
+		return
+	}
+	if !info.IsDir() {
+		if matchesExtension(info.Name(), w.extensions) {
+			w.report(p, info)
+		}
+		return
+	}
+
+	for _, dir := range ancestors {
+		if os.SameFile(dir, info) {
+			return
+		}
+	}
+	if w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth {
+		return
+	}
+	names, err := readDirNames(p)
+	if err != nil {
+		w.warn(p + ": " + err.Error())
+	}
+	ig.enter(p)
+	ancestors = append(ancestors[:len(ancestors):len(ancestors)], info)
+	for _, name := range names {
+		child := filepath.Join(p, name)
+		childInfo, err := os.Lstat(child)
+		if err != nil {
+			if !ig.ignored(child, false) {
+				w.warn(child + ": " + err.Error())
+			}
+			continue
+		}
+		w.walk(child, childInfo, depth+1, ancestors, ig)
+	}
+}
+
+// report calls found for a file not seen before, and alias for one already
+// reached by another path, through a link or a hard link.
+func (w *walker) report(p string, info os.FileInfo) {
+	link := info.Mode()&os.ModeSymlink != 0
+	if link {
+		target, err := os.Stat(p)
+		if err != nil {
+			w.found(p)
+			return
+		}
+		info = target
+	}
+	id, ok := identify(p, info)
+	if !ok {
+		w.found(p)
+		return
+	}
+	first, seen := w.seen[id]
+	if !seen && link && !w.recordAll {
+		// the target may have been reported under its own path, unrecorded
+		if first = w.reportedTarget(p); first != "" {
+			w.seen[id] = first
+			seen = true
+		}
+	}
+	if seen {
+		if w.alias != nil && p != first {
+			w.alias(p, first)
+		}
+		return
+	}
+	if link || w.recordAll || linkCount(info) > 1 {
+		w.seen[id] = p
+	}
+	w.found(p)
+}
+
+// reportedTarget returns the path the target of link p was found under when
+// the walk has already passed it inside a scanned directory, or "".
+func (w *walker) reportedTarget(p string) string {
+	target := resolvePath(p)
+	if target == "" {
+		return ""
+	}
+	for i, root := range w.roots[:w.root+1] {
+		if !root.dir || root.resolved == "" {
+			continue
+		}
+		rel := relInside(root.resolved, target)
+		if rel == "" || rel == "." {
+			continue
+		}
+		if i == w.root {
+			if current, err := filepath.Rel(root.path, p); err != nil || !walkedBefore(rel, current) {
+				return ""
+			}
+		}
+		if !w.walked(root, rel) {
+			return ""
+		}
+		return filepath.Join(root.path, rel)
+	}
+	return ""
+}
+
+// walked reports whether the walk of root reported the file at rel, which
+// it has already passed: every directory on the way was entered and neither
+// they nor the file are excluded.
+func (w *walker) walked(root walkRoot, rel string) bool {
+	parts := strings.Split(rel, string(filepath.Separator))
+	if w.opts.MaxDepth > 0 && len(parts) > w.opts.MaxDepth {
+		return false
+	}
+	if !matchesExtension(parts[len(parts)-1], w.extensions) {
+		return false
+	}
+	p := root.path
+	for i, part := range parts {
+		p = filepath.Join(p, part)
+		if root.ig.ignored(p, i < len(parts)-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// walkedBefore reports whether the walk reaches relative path a before b,
+// going through sorted directory entries depth first.
+func walkedBefore(a, b string) bool {
+	as := strings.Split(a, string(filepath.Separator))
+	bs := strings.Split(b, string(filepath.Separator))
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] != bs[i] {
+			return as[i] < bs[i]
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// readDirNames returns the sorted entry names of dir, as filepath.Walk does.
+func readDirNames(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.Readdirnames(-1)
+	f.Close()
+	sort.Strings(names)
+	return names, err
+}
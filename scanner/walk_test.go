@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanDirs_MaxDepth(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"top.json":       "{}",
+		"a/one.json":     "{}",
+		"a/b/two.json":   "{}",
+		"a/b/c/3.json":   "{}",
+		"x/y/z/w/4.json": "{}",
+	})
+
+	for depth, expected := range map[int][]string{
+		1: {"top.json"},
+		2: {"a/one.json", "top.json"},
+		3: {"a/b/two.json", "a/one.json", "top.json"},
+		0: {"a/b/c/3.json", "a/b/two.json", "a/one.json", "top.json", "x/y/z/w/4.json"},
+	} {
+		if got := scanRel(t, dir, Options{MaxDepth: depth}, ".json"); !reflect.DeepEqual(got, expected) {
+			t.Errorf("MaxDepth %d: expected %v, got %v", depth, expected, got)
+		}
+	}
+}
+
+func TestScanDirs_FollowSymlinks(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"sites-available/default.json": "{}",
+		"stow/app/config.json":         "{}",
+	})
+	os.Mkdir(filepath.Join(dir, "sites-enabled"), 0755)
+	os.Symlink(filepath.Join("..", "sites-available", "default.json"), filepath.Join(dir, "sites-enabled", "default.json"))
+	os.Symlink(filepath.Join(dir, "stow", "app"), filepath.Join(dir, "app"))
+	os.Symlink(dir, filepath.Join(dir, "stow", "loop")) // back to the top
+
+	expected := []string{"sites-available/default.json", "stow/app/config.json"}
+	if got := scanRel(t, dir, Options{}, ".json"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected linked directories to be left alone by default: expected %v, got %v", expected, got)
+	}
+
+	var found []string
+	aliases := make(map[string][]string)
+	errs := Walk(context.Background(), []string{dir}, []string{".json"}, Options{FollowSymlinks: true}, func(path string) {
+		found = append(found, path)
+	}, func(path, first string) {
+		aliases[first] = append(aliases[first], path)
+	})
+	if len(errs) != 0 {
+		t.Errorf("Expected 0 errors, got %v", errs)
+	}
+	expected = []string{filepath.Join(dir, "app", "config.json"), filepath.Join(dir, "sites-available", "default.json")}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Expected each file once, through the first path reaching it: expected %v, got %v", expected, found)
+	}
+	expectedAliases := map[string][]string{
+		filepath.Join(dir, "app", "config.json"):              {filepath.Join(dir, "stow", "app", "config.json")},
+		filepath.Join(dir, "sites-available", "default.json"): {filepath.Join(dir, "sites-enabled", "default.json")},
+	}
+	if !reflect.DeepEqual(aliases, expectedAliases) {
+		t.Errorf("Expected later paths as aliases: expected %v, got %v", expectedAliases, aliases)
+	}
+}
+
+func TestScanDirs_HardLinks(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.json": "{}"})
+	if err := os.Link(filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")); err != nil {
+		t.Skipf("hard links not supported: %v", err)
+	}
+	if got := scanRel(t, dir, Options{}, ".json"); !reflect.DeepEqual(got, []string{"a.json"}) {
+		t.Errorf("Expected a hard-linked file to be listed once, got %v", got)
+	}
+}
+
+// walkAll runs Walk over paths, returning what it found and the aliases of
+// each first path.
+func walkAll(t *testing.T, paths []string, opts Options) ([]string, map[string][]string) {
+	t.Helper()
+	var found []string
+	aliases := make(map[string][]string)
+	errs := Walk(context.Background(), paths, []string{".json"}, opts, func(path string) {
+		found = append(found, path)
+	}, func(path, first string) {
+		aliases[first] = append(aliases[first], path)
+	})
+	if len(errs) != 0 {
+		t.Errorf("Expected 0 errors, got %v", errs)
+	}
+	return found, aliases
+}
+
+func TestWalk_LinkedFiles(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"b/real.json":     "{}",
+		"excluded/x.json": "{}",
+	})
+	os.Symlink(filepath.Join(dir, "b", "real.json"), filepath.Join(dir, "a.json"))
+	os.Symlink(filepath.Join(dir, "b", "real.json"), filepath.Join(dir, "c.json"))
+	os.Symlink(filepath.Join(dir, "excluded", "x.json"), filepath.Join(dir, "x.json"))
+
+	found, aliases := walkAll(t, []string{dir}, Options{Exclude: []string{"excluded/"}})
+	expected := []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "x.json")}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Expected %v, got %v", expected, found)
+	}
+	expectedAliases := map[string][]string{
+		filepath.Join(dir, "a.json"): {filepath.Join(dir, "b", "real.json"), filepath.Join(dir, "c.json")},
+	}
+	if !reflect.DeepEqual(aliases, expectedAliases) {
+		t.Errorf("Expected aliases %v, got %v", expectedAliases, aliases)
+	}
+}
+
+func TestWalk_OverlappingPaths(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.json": "{}", "sub/b.json": "{}"})
+
+	found, aliases := walkAll(t, []string{dir, filepath.Join(dir, "sub")}, Options{})
+	expected := []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "sub", "b.json")}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Expected %v, got %v", expected, found)
+	}
+	if len(aliases) != 0 {
+		t.Errorf("Expected no aliases for the same path twice, got %v", aliases)
+	}
+}
+
+func TestWalk_RemembersOnlyLinkedFiles(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.json": "{}", "b.json": "{}", "c.json": "{}", "d.json": "{}"})
+	if err := os.Link(filepath.Join(dir, "a.json"), filepath.Join(dir, "hard.json")); err != nil {
+		t.Skipf("hard links not supported: %v", err)
+	}
+	os.Symlink(filepath.Join(dir, "b.json"), filepath.Join(dir, "soft.json"))
+
+	for _, tc := range []struct {
+		opts     Options
+		paths    []string
+		expected int
+	}{
+		{Options{}, []string{dir}, 2},
+		{Options{FollowSymlinks: true}, []string{dir}, 4},
+		{Options{}, []string{dir, dir}, 4},
+	} {
+		w := &walker{ctx: context.Background(), opts: tc.opts, extensions: []string{".json"}, found: func(string) {}, warn: func(string) {}}
+		w.run(tc.paths)
+		if len(w.seen) != tc.expected {
+			t.Errorf("%+v over %d paths: expected %d files remembered, got %d", tc.opts, len(tc.paths), tc.expected, len(w.seen))
+		}
+	}
+}